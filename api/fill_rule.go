@@ -0,0 +1,15 @@
+package api
+
+// FillRule decides which regions of an outline are inside when contours
+// overlap or self-intersect.
+type FillRule int
+
+const (
+	// FillEvenOdd treats a point as inside if a ray from it crosses the
+	// outline an odd number of times. Nested contours become holes.
+	FillEvenOdd FillRule = iota
+	// FillNonZero treats a point as inside if the outline winds around it
+	// a non zero number of times. Holes must be wound opposite to the
+	// contour that contains them.
+	FillNonZero
+)
@@ -0,0 +1,30 @@
+package api
+
+import "github.com/veandco/go-sdl2/sdl"
+
+// IApplication is user code driven by an ISurface. The surface owns the
+// window, event polling and frame loop and calls back into the
+// application at each stage.
+type IApplication interface {
+	// Init is called once by Run before the first frame. Returning an
+	// error stops Run.
+	Init(surface ISurface) error
+	// HandleEvent is called for each input event. Returning true marks
+	// the event as handled so the surface doesn't act on it.
+	HandleEvent(e sdl.Event) bool
+	// Update advances the application by a fixed step of 'dt' seconds.
+	Update(dt float64)
+	// Render draws the frame into the already cleared 'raster'. 'alpha'
+	// is how far, from 0 to 1, the frame is past the last update towards
+	// the next. Motion is smoothed by drawing the state interpolated by
+	// 'alpha' from the previous update to the last.
+	Render(raster IRasterBuffer, alpha float64)
+	// Shutdown is called once after the last frame.
+	Shutdown()
+}
+
+// IResizeHandler is optionally implemented by an IApplication to be told
+// when the surface reallocates its raster buffer at a new size.
+type IResizeHandler interface {
+	Resize(width, height int)
+}
@@ -0,0 +1,11 @@
+package api
+
+import "SoftRenderer/smath"
+
+// ICamera is a camera controller that produces a view matrix
+type ICamera interface {
+	// Update advances the camera by 'dt' seconds
+	Update(dt float64)
+	// View returns the world to camera transform
+	View() *smath.Matrix4
+}
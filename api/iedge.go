@@ -9,4 +9,5 @@ type IEdge interface {
 	YBot() int
 	Z1() float32
 	Z2() float32
+	Z() float32
 }
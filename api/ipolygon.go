@@ -12,6 +12,7 @@ package api
 type IPolygon interface {
 	AddVertex(x, y int, z float32)
 	AddTriangle(x1, y1, x2, y2, x3, y3 int, sharedE1, sharedE2, sharedE3 bool)
+	Triangulate() error
 	Draw(raster IRasterBuffer)
 	Fill(raster IRasterBuffer)
 }
@@ -0,0 +1,8 @@
+package api
+
+// IProfiler times named sections of a frame. Beginning a section ends the
+// running one so each moment is counted in exactly one section.
+type IProfiler interface {
+	Begin(section string)
+	End()
+}
@@ -5,16 +5,42 @@ import (
 	"image/color"
 )
 
-// IRasterBuffer api for color and depth buffer
+// IRasterBuffer api for color, depth and stencil buffer
 type IRasterBuffer interface {
 	EnableAlphaBlending(enable bool)
+	EnableColorWrite(enable bool)
+	ColorWriteEnabled() bool
 	Pixels() *image.RGBA
+	Depth() []float32
+	DepthAt(x, y int) float32
+	GetClearDepth() float32
 	Clear()
 	SetPixel(x, y int, z float32) int
 	SetPixelColor(c color.RGBA)
+	GetPixelColor() color.RGBA
+
+	EnableStencilTest(enable bool)
+	SetStencilFunc(fn StencilFunc, ref, mask uint8)
+	SetStencilOp(sfail, dpfail, dppass StencilOp)
+	SetStencilWriteMask(mask uint8)
+	ClearStencilBuffer()
 
 	DrawLine(xP, yP, xQ, yQ int, zP, zQ float32)
 	DrawLineAmmeraal(xP, yP, xQ, yQ int, zP, zQ float32)
 
+	SetLineWidth(width float32)
+	SetLineCap(lineCap LineCap)
+	SetLineJoin(join LineJoin)
+	SetLineDash(pattern []float32, offset float32)
+	DrawPolyline(points []float32, z float32, closed bool)
+
+	DrawCircle(xc, yc, r int, z float32)
+	FillCircle(xc, yc, r int, z float32)
+	DrawEllipse(xc, yc, rx, ry int, z float32)
+	FillEllipse(xc, yc, rx, ry int, z float32)
+	DrawArc(xc, yc, rx, ry int, start, end float32, z float32)
+	FillArc(xc, yc, rx, ry int, start, end float32, z float32)
+
 	FillTriangleAmmeraal(leftEdge, rightEdge IEdge, skipBottom, skipRight bool)
+	FillSpan(xl, xr, y int, zl, zr float32)
 }
@@ -0,0 +1,10 @@
+package api
+
+// IScanlineFiller fills arbitrary outlines, concave or self-intersecting,
+// made of one or more contours.
+type IScanlineFiller interface {
+	SetFillRule(rule FillRule)
+	AddContour(points []float32)
+	Reset()
+	Fill(raster IRasterBuffer, z float32)
+}
@@ -5,13 +5,33 @@ import "github.com/veandco/go-sdl2/sdl"
 // ISurface is the graph viewer
 type ISurface interface {
 	// Open(IHost)
-	Open()
-	Run()
+	Open() error
+	Run() error
 	Close()
+	ToggleFullscreen() error
 	Quit()
 	Configure()
 	SetFont(fontPath string, size int) error
 
+	// SetApplication sets the application Run drives
+	SetApplication(app IApplication)
+	RasterBuffer() IRasterBuffer
+	Profiler() IProfiler
+
+	// Screenshot saves the next frame as an image
+	Screenshot(includeHUD bool)
+	// CaptureFrames saves the next n frames as numbered images
+	CaptureFrames(n int, includeHUD bool)
+	// SaveDepth writes the last frame's depth buffer
+	SaveDepth(path string) error
+	// ShowDepth displays a visualization of the depth buffer
+	ShowDepth(show bool)
+	// SaveDepthImage writes the last frame's depth visualization
+	SaveDepthImage(path string) error
+	// StartRecording and StopRecording record an animated GIF
+	StartRecording()
+	StopRecording()
+
 	SetDrawColor(color sdl.Color)
 	SetPixel(x, y int)
 }
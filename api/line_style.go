@@ -0,0 +1,27 @@
+package api
+
+// LineCap is the shape drawn at the open ends of a polyline and at
+// the ends of each dash.
+type LineCap int
+
+// LineJoin is the shape drawn where two polyline segments meet.
+type LineJoin int
+
+const (
+	// CapButt ends the line flush with its end point
+	CapButt LineCap = iota
+	// CapRound ends the line with a half disc centered on the end point
+	CapRound
+	// CapSquare extends the line by half its width past the end point
+	CapSquare
+)
+
+const (
+	// JoinMiter extends the outer edges until they meet, falling back to
+	// a bevel when the miter limit is exceeded
+	JoinMiter LineJoin = iota
+	// JoinRound fills a disc centered on the shared vertex
+	JoinRound
+	// JoinBevel connects the outer corners with a straight edge
+	JoinBevel
+)
@@ -0,0 +1,22 @@
+package api
+
+// RenderMode selects how a primitive is rasterized.
+type RenderMode int
+
+const (
+	// RenderFill fills the primitive with the buffer's pixel color
+	RenderFill RenderMode = iota
+	// RenderDepth writes only depth. Rendering every primitive this way
+	// before RenderWireframe gives hidden line removal.
+	RenderDepth
+	// RenderWireframe draws only the edges, depth tested and biased
+	// against whatever is already in the depth buffer.
+	RenderWireframe
+	// RenderFillWireframe fills the primitive and then overlays its
+	// biased edges.
+	RenderFillWireframe
+	// RenderHiddenLine primes depth and then draws the biased edges in
+	// a single call. Hidden lines are only removed for primitives drawn
+	// front to back, otherwise use RenderDepth and RenderWireframe passes.
+	RenderHiddenLine
+)
@@ -0,0 +1,44 @@
+package api
+
+// StencilFunc is the comparison used by the stencil test. The masked
+// reference value is compared against the masked stencil value, for
+// example, StencilLess passes when (ref & mask) < (stencil & mask).
+type StencilFunc int
+
+// StencilOp is the action applied to a stencil value after the stencil
+// and depth tests.
+type StencilOp int
+
+const (
+	// StencilNever always fails
+	StencilNever StencilFunc = iota
+	// StencilLess passes if ref < stencil
+	StencilLess
+	// StencilLEqual passes if ref <= stencil
+	StencilLEqual
+	// StencilGreater passes if ref > stencil
+	StencilGreater
+	// StencilGEqual passes if ref >= stencil
+	StencilGEqual
+	// StencilEqual passes if ref == stencil
+	StencilEqual
+	// StencilNotEqual passes if ref != stencil
+	StencilNotEqual
+	// StencilAlways always passes
+	StencilAlways
+)
+
+const (
+	// StencilKeep leaves the stencil value unchanged
+	StencilKeep StencilOp = iota
+	// StencilZero sets the stencil value to 0
+	StencilZero
+	// StencilReplace sets the stencil value to the reference value
+	StencilReplace
+	// StencilIncr increments the stencil value clamping at 255
+	StencilIncr
+	// StencilDecr decrements the stencil value clamping at 0
+	StencilDecr
+	// StencilInvert bitwise inverts the stencil value
+	StencilInvert
+)
@@ -0,0 +1,452 @@
+package main
+
+import (
+	"SoftRenderer/api"
+	graphics "SoftRenderer/graphcs"
+	"SoftRenderer/renderer"
+	"SoftRenderer/scene"
+	"SoftRenderer/smath"
+	"image/color"
+	"math"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Moon orbit rate in radians per second
+const moonSpeed = 1.0
+
+// app draws rasterizer test shapes and an arcball/camera controlled scene.
+type app struct {
+	surface    api.ISurface
+	profiler   api.IProfiler
+	rasterizer api.IRasterizer
+	text       *graphics.TextRenderer
+
+	width  int
+	height int
+
+	arcBall  *graphics.ArcBall
+	mousePos smath.Vector3
+
+	// Scene graph: a box rotated by the arcball with a moon orbiting it
+	root          *scene.Node
+	box           *scene.Node
+	moonPivot     *scene.Node
+	sceneRenderer *scene.Renderer
+	sceneCamera   *scene.Camera
+
+	// Cameras. C switches between them.
+	flyCamera   *graphics.FlyCamera
+	orbitCamera *graphics.OrbitCamera
+	camera      api.ICamera
+
+	keyState []uint8
+
+	// Animation of the split triangles
+	mod  int
+	dir  int
+	dir2 int
+	dir3 int
+	xx   int
+	xx2  int
+	xx3  int
+
+	// Positions before the last update, for interpolation
+	prevXX  int
+	prevXX2 int
+	prevXX3 int
+
+	animate bool
+	step    bool
+}
+
+func newApp() *app {
+	a := new(app)
+	a.animate = true
+	a.step = false
+	a.mod = 200
+	a.xx = 75 // -41 //75 // x2
+	a.dir = 1
+	a.xx2 = 0 //-29 //0 // x1
+	a.dir2 = 1
+	a.xx3 = 100 //28 //100 // y1
+	a.dir3 = 1
+	//x1  -29 y1  8 x2  -41
+	a.prevXX = a.xx
+	a.prevXX2 = a.xx2
+	a.prevXX3 = a.xx3
+	return a
+}
+
+// Init sets up the controllers for the surface's size.
+func (a *app) Init(surface api.ISurface) error {
+	a.surface = surface
+	a.profiler = surface.Profiler()
+	a.rasterizer = renderer.NewBresenHamRasterizer()
+
+	// Drawn into the raster, in front of everything
+	a.text = graphics.NewTextRenderer(nil)
+	a.text.Align = graphics.AlignRight
+	a.text.Color = color.RGBA{R: 180, G: 180, B: 180, A: 255}
+	a.text.Z = 1.0e8
+
+	a.arcBall = graphics.NewArcBall()
+	bounds := surface.RasterBuffer().Pixels().Bounds()
+	a.Resize(bounds.Dx(), bounds.Dy())
+
+	a.flyCamera = graphics.NewFlyCamera()
+	a.flyCamera.SetPosition(0.0, 0.0, 5.0)
+	a.orbitCamera = graphics.NewOrbitCamera()
+	a.camera = a.orbitCamera
+
+	a.buildScene()
+
+	// Get a reference to SDL's internal keyboard state. It is updated
+	// during sdl.PumpEvents()
+	a.keyState = sdl.GetKeyboardState()
+	return nil
+}
+
+// Resize keeps the arcball centered when the raster is reallocated.
+func (a *app) Resize(width, height int) {
+	a.width = width
+	a.height = height
+	a.arcBall.SetCanvasSize(float64(width), float64(height))
+	a.arcBall.Place(smath.NewVector3With2Components(float64(width)/2, float64(height)/2), float64(height)/3)
+}
+
+// HandleEvent steers the arcball and cameras.
+func (a *app) HandleEvent(e sdl.Event) bool {
+	switch t := e.(type) {
+	case *sdl.MouseMotionEvent:
+		a.mousePos.Set2Components(float64(t.X), float64(t.Y))
+		a.arcBall.Mouse(&a.mousePos)
+
+		// Right drag looks or orbits, middle drag pans.
+		dx := float64(t.XRel)
+		dy := float64(t.YRel)
+		if t.State&sdl.ButtonRMask() != 0 {
+			if a.camera == a.flyCamera {
+				a.flyCamera.Look(dx, dy)
+			} else {
+				a.orbitCamera.Rotate(dx, dy)
+			}
+		}
+		if t.State&sdl.ButtonMMask() != 0 && a.camera == a.orbitCamera {
+			a.orbitCamera.Pan(dx, dy)
+		}
+		return true
+	case *sdl.MouseButtonEvent:
+		if t.Button == sdl.BUTTON_LEFT {
+			a.mousePos.Set2Components(float64(t.X), float64(t.Y))
+			a.arcBall.Mouse(&a.mousePos)
+			if t.State == sdl.PRESSED {
+				a.arcBall.BeginDrag()
+			} else {
+				a.arcBall.EndDrag()
+			}
+		}
+		return true
+	case *sdl.MouseWheelEvent:
+		notches := float64(t.Y)
+		if t.Direction == sdl.MOUSEWHEEL_FLIPPED {
+			notches = -notches
+		}
+		a.orbitCamera.Zoom(notches)
+		return true
+	case *sdl.KeyboardEvent:
+		if t.State != sdl.PRESSED {
+			return false
+		}
+		switch t.Keysym.Scancode {
+		case sdl.SCANCODE_SPACE:
+			a.animate = !a.animate
+		case sdl.SCANCODE_RETURN:
+			a.step = true
+		case sdl.SCANCODE_C:
+			if a.camera == a.flyCamera {
+				a.camera = a.orbitCamera
+			} else {
+				a.camera = a.flyCamera
+			}
+		default:
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// Update moves the arcball, active camera and animation.
+func (a *app) Update(dt float64) {
+	keyState := a.keyState
+	if keyState[sdl.SCANCODE_Z] != 0 {
+		a.mod--
+	}
+	if keyState[sdl.SCANCODE_X] != 0 {
+		a.mod++
+	}
+
+	// Shift constrains the arcball to screen axes, Ctrl to the
+	// object's axes.
+	switch {
+	case keyState[sdl.SCANCODE_LSHIFT] != 0 || keyState[sdl.SCANCODE_RSHIFT] != 0:
+		a.arcBall.UseSet(graphics.ArcBallCameraAxes)
+	case keyState[sdl.SCANCODE_LCTRL] != 0 || keyState[sdl.SCANCODE_RCTRL] != 0:
+		a.arcBall.UseSet(graphics.ArcBallBodyAxes)
+	default:
+		a.arcBall.UseSet(graphics.ArcBallNoAxes)
+	}
+	a.arcBall.Update()
+
+	a.flyCamera.Move(
+		keyAxis(keyState, sdl.SCANCODE_W, sdl.SCANCODE_S),
+		keyAxis(keyState, sdl.SCANCODE_D, sdl.SCANCODE_A),
+		keyAxis(keyState, sdl.SCANCODE_E, sdl.SCANCODE_Q))
+	a.camera.Update(dt)
+
+	if a.animate || a.step {
+		a.moonPivot.Rotate(yaw(moonSpeed * dt))
+	}
+	a.animateTriangle()
+}
+
+// Shutdown has nothing to release.
+func (a *app) Shutdown() {
+}
+
+// keyAxis returns 1 if 'pos' is held, -1 if 'neg' is held, otherwise 0.
+func keyAxis(keyState []uint8, pos, neg sdl.Scancode) float64 {
+	a := 0.0
+	if keyState[pos] != 0 {
+		a++
+	}
+	if keyState[neg] != 0 {
+		a--
+	}
+	return a
+}
+
+// animateTriangle moves the split bottom triangle's vertices one step.
+func (a *app) animateTriangle() {
+	a.prevXX = a.xx
+	a.prevXX2 = a.xx2
+	a.prevXX3 = a.xx3
+
+	if !a.animate && !a.step {
+		return
+	}
+	a.step = false
+
+	if a.xx2 < -50 {
+		a.dir2 = 2
+	} else if a.xx2 > 100 {
+		a.dir2 = -2
+	}
+	a.xx2 += a.dir2
+
+	if a.xx3 < 0 {
+		a.dir3 = 1
+	} else if a.xx3 > 100 {
+		a.dir3 = -1
+	}
+	a.xx3 += a.dir3
+
+	if a.xx < -50 {
+		a.dir = 1
+	} else if a.xx > 100 {
+		a.dir = -1
+	}
+	a.xx += a.dir
+}
+
+// lerp interpolates from 'prev' to 'cur' by 'alpha'.
+func lerp(prev, cur int, alpha float64) int {
+	return int(math.Round(float64(prev) + float64(cur-prev)*alpha))
+}
+
+// Render draws the test lines, triangles and scene.
+func (a *app) Render(raster api.IRasterBuffer, alpha float64) {
+	a.profiler.Begin("raster")
+	rasterizer := a.rasterizer
+	// c := color.RGBA{R: 255, G: 127, B: 0, A: 255}
+	// This full loop takes about 20ms for an 800x800 dimension.
+	// for y := 0; y < height; y++ {
+	// 	for x := 0; x < width; x++ {
+	// 		c.R = uint8(x % a.mod)
+	// 		c.G = uint8(y % a.mod)
+	// 		raster.SetPixelColor(c)
+	// 		raster.SetPixel(x, y, 0.0)
+	// 	}
+	// }
+
+	x := 0
+	y := 0
+	left := false
+	rasterizer.DrawLineAmmeraal(raster, left, x, y, x+100, y+25) // blue dx>0
+
+	x = 0
+	y = 0
+	rasterizer.DrawLineAmmeraal(raster, left, x, y+25, x+100, y) // blue dx>0
+
+	x = 50
+	y = 50
+	down := true
+	rasterizer.DrawLineAmmeraal(raster, down, x, y+50, x+50, y+150) // red
+	x = 50
+	y = 50
+	rasterizer.DrawLineAmmeraal(raster, down, x+50, y+50, x, y+150) // red
+
+	// Horizontal
+	x = 100
+	y = 5
+	left = false
+	rasterizer.DrawLineAmmeraal(raster, left, x, y, x+100, y) // blue
+	x = 100
+	y = 10
+	left = true
+	rasterizer.DrawLineAmmeraal(raster, left, x, y, x+100, y) // blue
+
+	// Vertical
+	x = 100
+	y = 20
+	// down = false
+	// rasterizer.DrawLineAmmeraal(v, down, x, y+100, x, y) // red
+	// Or
+	down = true
+	rasterizer.DrawLineAmmeraal(raster, down, x, y, x, y+100) // red
+	x = 110
+	y = 20
+	down = false
+	rasterizer.DrawLineAmmeraal(raster, down, x, y, x, y+100) // red
+
+	raster.SetPixelColor(color.RGBA{R: 255, G: 255, B: 255, A: 255})
+
+	// Triangle flat-bottom ----------------------------------
+	x = 200
+	y = 25
+
+	x1 := 0
+	y1 := 50
+	x2 := 50
+	y2 := 50
+	x3 := 25
+	y3 := 0
+	// Make sure Y's are consitent
+	// rasterizer.Sort(&x1, &y1, &x2, &y2, &x3, &y3)
+
+	tri := graphics.NewTriangle()
+
+	down = false
+	// rasterizer.DrawLineAmmeraal(raster, left, x+x1, y+y1, x+x2, y+y2) // blue horz
+	// rasterizer.DrawLineAmmeraal(raster, down, x+x3, y+y3, x+x2, y+y2) // red
+	// rasterizer.DrawLineAmmeraal(raster, down, x+x3, y+y3, x+x1, y+y1) // red
+	tri.Set(x+x1, y+y1, x+x2, y+y2, x+x3, y+y3)
+	tri.Fill(raster)
+
+	raster.SetPixelColor(color.RGBA{R: 0, G: 255, B: 255, A: 127})
+	// Triangle flat-top ----------------------------------
+	x = 200
+	y = 50
+
+	x1 = 25
+	y1 = 50
+	x2 = 0
+	y2 = 0
+	x3 = 50
+	y3 = 0
+	// Make sure Y's are consitent
+	// rasterizer.Sort(&x1, &y1, &x2, &y2, &x3, &y3)
+
+	down = false
+	// rasterizer.DrawLineAmmeraal(raster, left, x+x1, y+y1, x+x2, y+y2) // blue horz
+	// rasterizer.DrawLineAmmeraal(raster, down, x+x2, y+y2, x+x3, y+y3) // red
+	// rasterizer.DrawLineAmmeraal(raster, down, x+x3, y+y3, x+x1, y+y1) // red
+
+	tri.SetWithZ(x+x1, y+y1, 2.0, x+x2, y+y2, 2.0, x+x3, y+y3, 2.0)
+	tri.Fill(raster)
+
+	raster.SetPixelColor(color.RGBA{R: 255, G: 255, B: 255, A: 255})
+
+	// Triangle split top ----------------------------------
+	x = 200
+	y = 200
+
+	x1 = 25
+	y1 = 50
+	x2 = 0
+	y2 = -50
+	x3 = 50
+	y3 = 0
+	tri.Set(x+x1, y+y1, x+x2, y+y2, x+x3, y+y3)
+	tri.Fill(raster)
+
+	// Triangle split bottom ----------------------------------
+	x = 350
+	y = 200
+
+	x1 = lerp(a.prevXX2, a.xx2, alpha)
+	//y1 = 100
+	y1 = lerp(a.prevXX3, a.xx3, alpha)
+	x2 = lerp(a.prevXX, a.xx, alpha) // 75 cause overdraw, 50 is fine
+	// fmt.Println("x1 ", x1, "y1 ", y1, "x2 ", x2)
+	y2 = 50
+	x3 = 25
+	y3 = 0
+	// fmt.Println(x+x1, y+y1, x+x2, y+y2, x+x3, y+y3)
+
+	tri.Set(x+x1, y+y1, x+x2, y+y2, x+x3, y+y3)
+	tri.Fill(raster)
+
+	a.renderScene(raster)
+
+	a.text.Draw(raster, "Drag: rotate  C: camera\nF3: profiler  F11: fullscreen", a.width-4, 4)
+
+	// Drawn in front of everything
+	a.arcBall.DrawOverlay(raster, 1.0e8)
+}
+
+// buildScene creates the box, its orbiting moon and a light.
+func (a *app) buildScene() {
+	a.root = scene.NewNode("root")
+
+	a.box = scene.NewNode("box")
+	a.box.Mesh = scene.NewBoxMesh(2.0, 2.0, 2.0)
+	a.box.Mesh.Color = color.RGBA{R: 255, G: 200, B: 0, A: 255}
+	a.root.AddChild(a.box)
+
+	// The pivot is a child of the box so the moon follows the
+	// arcball, and it spins to carry the moon around.
+	a.moonPivot = scene.NewNode("moon pivot")
+	a.box.AddChild(a.moonPivot)
+
+	moon := scene.NewNode("moon")
+	moon.Mesh = scene.NewBoxMesh(1.0, 1.0, 1.0)
+	moon.Mesh.Color = color.RGBA{R: 120, G: 180, B: 255, A: 255}
+	moon.SetPosition(2.5, 0.0, 0.0)
+	moon.SetScale(0.5, 0.5, 0.5)
+	a.moonPivot.AddChild(moon)
+
+	// Shining down and from the front
+	sun := scene.NewNode("sun")
+	sun.Light = scene.NewLight(scene.DirectionalLight)
+	sun.SetRotation(smath.Quaternion{W: math.Cos(-math.Pi / 8.0), X: math.Sin(-math.Pi / 8.0)})
+	a.root.AddChild(sun)
+
+	a.sceneRenderer = scene.NewRenderer()
+	a.sceneRenderer.Mode = api.RenderFillWireframe
+	a.sceneCamera = scene.NewCamera()
+}
+
+// yaw returns a rotation of 'angle' radians about +Y.
+func yaw(angle float64) smath.Quaternion {
+	return smath.Quaternion{W: math.Cos(angle / 2.0), Y: math.Sin(angle / 2.0)}
+}
+
+// renderScene draws the scene graph rotated by the arcball and viewed
+// through the active camera.
+func (a *app) renderScene(raster api.IRasterBuffer) {
+	a.profiler.Begin("scene")
+	a.box.SetRotation(a.arcBall.Rotation())
+	a.sceneRenderer.RenderView(raster, a.root, a.camera.View(), a.sceneCamera)
+}
@@ -1,14 +1,25 @@
 package main
 
-import "SoftRenderer/surface"
+import (
+	"SoftRenderer/surface"
+	"flag"
+)
 
 func main() {
-	surface := surface.NewSurfaceBuffer()
+	config := surface.DefaultConfig()
+	config.Title = "Triangle rasterizer"
+	config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
+	surface := surface.NewSurfaceBufferWithConfig(config)
 	defer surface.Close()
 
-	surface.Open()
+	err := surface.Open()
+	if err != nil {
+		panic(err)
+	}
 
-	err := surface.SetFont("../../assets/MontserratAlternates-Light.otf", 16)
+	err = surface.SetFont("../../assets/MontserratAlternates-Light.otf", 16)
 
 	if err != nil {
 		panic(err)
@@ -16,5 +27,10 @@ func main() {
 
 	surface.Configure()
 
-	surface.Run()
+	surface.SetApplication(newApp())
+
+	err = surface.Run()
+	if err != nil {
+		panic(err)
+	}
 }
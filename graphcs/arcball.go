@@ -1,12 +1,29 @@
 package graphics
 
-import "SoftRenderer/smath"
+import (
+	"SoftRenderer/api"
+	"SoftRenderer/smath"
+	"image/color"
+	"math"
+)
 
 const (
 	LGNSegs = 4
 	NSEGS   = 1 << LGNSegs
 )
 
+// ArcBallAxes selects the set of axes a drag can be constrained to.
+type ArcBallAxes int
+
+const (
+	// ArcBallNoAxes rotates freely
+	ArcBallNoAxes ArcBallAxes = iota
+	// ArcBallCameraAxes constrains to the screen's x, y and z axes
+	ArcBallCameraAxes
+	// ArcBallBodyAxes constrains to the object's own axes
+	ArcBallBodyAxes
+)
+
 // ArcBall is a basic camera control
 type ArcBall struct {
 	Radius float64
@@ -26,6 +43,14 @@ type ArcBall struct {
 
 	dragging bool
 
+	// Constraint axes. axisIndex is -1 when rotation is unconstrained.
+	axisSet   ArcBallAxes
+	axes      [3]smath.Vector3
+	axisIndex int
+
+	// Scratch polyline for DrawOverlay
+	overlay []float32
+
 	vBallMouse    smath.Vector3
 	q             smath.Quaternion
 	qConj         smath.Quaternion
@@ -67,39 +92,101 @@ type ArcBall struct {
 // NewArcBall creates Ken's arc ball
 func NewArcBall() *ArcBall {
 	ab := new(ArcBall)
+	ab.Radius = 1.0
+	ab.qNow.W = 1.0
+	ab.qDown.W = 1.0
+	ab.qDrag.W = 1.0
+	ab.mNow.ToIdentity()
+	ab.mDown.ToIdentity()
+	ab.axisIndex = -1
+	for i := range ab.pts {
+		ab.pts[i] = smath.NewVector3()
+	}
 	return ab
 }
 
+// SetCanvasSize sets the size of the screen area the mouse moves within.
+// It is needed to flip mouse coordinates when +y is downward.
+func (ab *ArcBall) SetCanvasSize(width, height float64) {
+	ab.pCanvasSize.Set3Components(width, height, 0.0)
+}
+
 // Place sets the center and size of the controller.
+// The center is in screen coordinates, the same as the mouse.
 func (ab *ArcBall) Place(v *smath.Vector3, r float64) {
 	ab.vCanvasCenter.Set(v)
 	ab.Radius = r
 }
 
-func (ab *ArcBall) remapScreenCoords(p *smath.Vector3) {
+func (ab *ArcBall) remapScreenCoords(p, out *smath.Vector3) {
 	if !ab.screenYOrientation {
-		ab.vNow.Set3Components(p.X, ab.pCanvasSize.Y-p.Y, 0.0)
+		out.Set3Components(p.X, ab.pCanvasSize.Y-p.Y, 0.0)
 	} else {
-		ab.vNow.Set3Components(p.X, p.Y, 0.0)
+		out.Set3Components(p.X, p.Y, 0.0)
 	}
 }
 
-// Mouse incorporatea the given mouse position.
+// Mouse incorporates the given mouse position.
 func (ab *ArcBall) Mouse(mp *smath.Vector3) {
-	ab.remapScreenCoords(mp)
+	ab.remapScreenCoords(mp, &ab.vNow)
 }
 
-// Update updates the arcball
+// UseSet selects the axes that drags are constrained to. While not
+// dragging the axis nearest the mouse is chosen on each Update.
+func (ab *ArcBall) UseSet(axes ArcBallAxes) {
+	if ab.dragging {
+		// Switching sets mid drag would make the ball jump.
+		return
+	}
+	ab.axisSet = axes
+	ab.loadAxes()
+}
+
+// BeginDrag starts a rotation at the current mouse position.
+func (ab *ArcBall) BeginDrag() {
+	ab.dragging = true
+	ab.vDown.Set(&ab.vNow)
+}
+
+// EndDrag finishes a rotation keeping the current orientation.
+func (ab *ArcBall) EndDrag() {
+	ab.dragging = false
+	ab.qDown.Set(&ab.qNow)
+	ab.mDown.Set(&ab.mNow)
+	ab.loadAxes()
+}
+
+// Dragging returns true while a drag is in progress.
+func (ab *ArcBall) Dragging() bool {
+	return ab.dragging
+}
+
+// Update updates the arcball from the latest mouse position.
 func (ab *ArcBall) Update() {
-	// ab.vTo.Set(ab.mouseOnSphere(ab.vNow))
+	ab.mouseOnSphere(&ab.vDown, &ab.vFrom)
+	ab.mouseOnSphere(&ab.vNow, &ab.vTo)
 
-	// if ab.dragging {
-	// 	qDrag = ab.mapFromBallPoints(ab.vFrom, ab.vTo)
-	// 	ab.qNow.mul(ab.qDrag, ab.qDown)
-	// }
+	if ab.dragging {
+		if ab.axisIndex >= 0 {
+			constrainToAxis(&ab.vFrom, &ab.axes[ab.axisIndex])
+			constrainToAxis(&ab.vTo, &ab.axes[ab.axisIndex])
+		}
+		ab.mapFromBallPoints(&ab.vFrom, &ab.vTo, &ab.qDrag)
+		ab.qNow = smath.Prod(ab.qDrag, ab.qDown)
+	} else if ab.axisSet != ArcBallNoAxes {
+		ab.axisIndex = ab.nearestConstraintAxis(&ab.vTo)
+	} else {
+		ab.axisIndex = -1
+	}
+
+	ab.mapToBallPoints(&ab.qDown, &ab.vrFrom, &ab.vrTo)
+	ab.mNow.SetRotationFromQuaternion(&ab.qNow)
+	ab.q.Set(&ab.qNow)
+}
 
-	// ab.mapToBallPoints(ab.qDown, ab.vrFrom, ab.vrTo)
-	// ab.q.Set(&ab.qNow)
+// Rotation returns the ball's current rotation
+func (ab *ArcBall) Rotation() smath.Quaternion {
+	return ab.qNow
 }
 
 // GetMatrix returns the ball's equivalent matrix
@@ -107,6 +194,254 @@ func (ab *ArcBall) GetMatrix() *smath.Matrix4 {
 	return &ab.mNow
 }
 
+// RimPoints appends the ball's outline to 'out' as x,y screen pairs and
+// returns the closed polyline.
+func (ab *ArcBall) RimPoints(out []float32) []float32 {
+	// Four quarter arcs around the great circle facing the viewer
+	var a, b smath.Vector3
+	a.Set3Components(1.0, 0.0, 0.0)
+	for i := 0; i < 4; i++ {
+		b.Set3Components(-a.Y, a.X, 0.0)
+		out = ab.anyArc(&a, &b, out, i > 0)
+		a.Set(&b)
+	}
+	return out
+}
+
+// ConstraintPoints appends the half circle, on the front of the ball,
+// that constraint axis 'i' rotates points along. Nothing is appended if
+// no axis set is in use.
+func (ab *ArcBall) ConstraintPoints(i int, out []float32) []float32 {
+	if ab.axisSet == ArcBallNoAxes || i < 0 || i >= len(ab.axes) {
+		return out
+	}
+	return ab.halfArc(&ab.axes[i], out)
+}
+
+// ConstraintAxis returns the index of the axis the next or current drag is
+// constrained to, or -1 if it is free.
+func (ab *ArcBall) ConstraintAxis() int {
+	return ab.axisIndex
+}
+
+// DragArcPoints appends the arc from where the drag started to the
+// current mouse position. Nothing is appended when not dragging.
+func (ab *ArcBall) DragArcPoints(out []float32) []float32 {
+	if !ab.dragging {
+		return out
+	}
+	return ab.anyArc(&ab.vFrom, &ab.vTo, out, false)
+}
+
+// ResultArcPoints appends the arc representing the orientation at the
+// start of the drag.
+func (ab *ArcBall) ResultArcPoints(out []float32) []float32 {
+	return ab.anyArc(&ab.vrFrom, &ab.vrTo, out, false)
+}
+
+// DrawOverlay draws the rim, constraint axes and drag arc into 'raster'
+// using the raster's current line style. Each layer is drawn slightly in
+// front of the one before it, starting at depth z.
+func (ab *ArcBall) DrawOverlay(raster api.IRasterBuffer, z float32) {
+	// Layers are a unit apart, or more where float32 can't resolve a
+	// unit. Ties are rejected by the depth test.
+	step := float32(math.Max(1.0, math.Abs(float64(z))*1.0e-6))
+
+	ab.overlay = ab.RimPoints(ab.overlay[:0])
+	raster.SetPixelColor(color.RGBA{R: 128, G: 128, B: 128, A: 255})
+	raster.DrawPolyline(ab.overlay, z, true)
+
+	for i := range ab.axes {
+		ab.overlay = ab.ConstraintPoints(i, ab.overlay[:0])
+		if i == ab.axisIndex {
+			raster.SetPixelColor(color.RGBA{R: 255, G: 255, B: 0, A: 255})
+			raster.DrawPolyline(ab.overlay, z+2*step, false)
+		} else {
+			raster.SetPixelColor(color.RGBA{R: 0, G: 96, B: 160, A: 255})
+			raster.DrawPolyline(ab.overlay, z+step, false)
+		}
+	}
+
+	ab.overlay = ab.DragArcPoints(ab.overlay[:0])
+	raster.SetPixelColor(color.RGBA{R: 255, G: 64, B: 64, A: 255})
+	raster.DrawPolyline(ab.overlay, z+3*step, false)
+}
+
+// anyArc appends the great circle arc between two points on the ball,
+// subdivided into NSEGS segments. If 'skipFirst' is true the start point
+// is left out so arcs can be chained.
+func (ab *ArcBall) anyArc(from, to *smath.Vector3, out []float32, skipFirst bool) []float32 {
+	pts := &ab.pts
+	pts[0].Set(from)
+	pts[1].Set(to)
+	pts[NSEGS].Set(to)
+
+	// Halving the arc LGNSegs times gives the first step.
+	for i := 0; i < LGNSegs; i++ {
+		bisect(pts[0], pts[1], pts[1])
+	}
+
+	// Each remaining point follows from the two before it.
+	dot := 2.0 * pts[0].Dot(pts[1])
+	for i := 2; i < NSEGS; i++ {
+		p := pts[i]
+		p.Set(pts[i-1])
+		p.ScaleBy(dot)
+		p.Sub(pts[i-2])
+	}
+
+	i := 0
+	if skipFirst {
+		i = 1
+	}
+	for ; i <= NSEGS; i++ {
+		out = ab.ballToScreen(pts[i], out)
+	}
+	return out
+}
+
+// halfArc appends the front half of the great circle perpendicular to 'n'.
+func (ab *ArcBall) halfArc(n *smath.Vector3, out []float32) []float32 {
+	var p, m smath.Vector3
+	if math.Abs(n.Z) < 1.0-smath.Epsilon {
+		p.Set3Components(n.Y, -n.X, 0.0)
+		p.Normalize()
+	} else {
+		p.Set3Components(0.0, 1.0, 0.0)
+	}
+	m.Set(&p)
+	m.Cross(n)
+
+	out = ab.anyArc(&p, &m, out, false)
+	p.ScaleBy(-1.0)
+	return ab.anyArc(&m, &p, out, true)
+}
+
+// ballToScreen appends the screen position of a point on the unit ball.
+func (ab *ArcBall) ballToScreen(v *smath.Vector3, out []float32) []float32 {
+	y := v.Y * ab.Radius
+	if !ab.screenYOrientation {
+		y = -y
+	}
+	return append(out,
+		float32(ab.vCanvasCenter.X+v.X*ab.Radius),
+		float32(ab.vCanvasCenter.Y+y))
+}
+
+// bisect sets 'out' to the point on the ball halfway between v0 and v1.
+func bisect(v0, v1, out *smath.Vector3) {
+	out.Set3Components(v0.X+v1.X, v0.Y+v1.Y, v0.Z+v1.Z)
+	n := out.LengthSquared()
+	if n < 1.0e-5 {
+		out.Set3Components(0.0, 0.0, 1.0)
+	} else {
+		out.ScaleBy(1.0 / math.Sqrt(n))
+	}
+}
+
+// loadAxes fills in the axes of the current set. Body axes are the
+// columns of the rotation at the end of the last drag.
+func (ab *ArcBall) loadAxes() {
+	switch ab.axisSet {
+	case ArcBallCameraAxes:
+		ab.axes[0].Set3Components(1.0, 0.0, 0.0)
+		ab.axes[1].Set3Components(0.0, 1.0, 0.0)
+		ab.axes[2].Set3Components(0.0, 0.0, 1.0)
+	case ArcBallBodyAxes:
+		ab.axes[0].Set3Components(ab.mDown.C(smath.M00), ab.mDown.C(smath.M10), ab.mDown.C(smath.M20))
+		ab.axes[1].Set3Components(ab.mDown.C(smath.M01), ab.mDown.C(smath.M11), ab.mDown.C(smath.M21))
+		ab.axes[2].Set3Components(ab.mDown.C(smath.M02), ab.mDown.C(smath.M12), ab.mDown.C(smath.M22))
+	default:
+		ab.axisIndex = -1
+	}
+}
+
+// mouseOnSphere maps a (remapped) mouse position onto the unit ball.
+// Positions outside the ball are pulled onto its rim.
+func (ab *ArcBall) mouseOnSphere(mouse, out *smath.Vector3) {
+	ab.remapScreenCoords(&ab.vCanvasCenter, &ab.vBase)
+
+	x := (mouse.X - ab.vBase.X) / ab.Radius
+	y := (mouse.Y - ab.vBase.Y) / ab.Radius
+	mag := x*x + y*y
+	if mag > 1.0 {
+		s := 1.0 / math.Sqrt(mag)
+		out.Set3Components(x*s, y*s, 0.0)
+	} else {
+		out.Set3Components(x, y, math.Sqrt(1.0-mag))
+	}
+}
+
+// mapFromBallPoints forms the unit quaternion rotating twice the arc
+// between two points on the ball.
+func (ab *ArcBall) mapFromBallPoints(from, to *smath.Vector3, q *smath.Quaternion) {
+	q.X = from.Y*to.Z - from.Z*to.Y
+	q.Y = from.Z*to.X - from.X*to.Z
+	q.Z = from.X*to.Y - from.Y*to.X
+	q.W = from.Dot(to)
+}
+
+// mapToBallPoints is the inverse of mapFromBallPoints. It converts a
+// unit quaternion into the end points of an arc on the ball.
+func (ab *ArcBall) mapToBallPoints(q *smath.Quaternion, arcFrom, arcTo *smath.Vector3) {
+	s := math.Sqrt(q.X*q.X + q.Y*q.Y)
+	if s == 0.0 {
+		arcFrom.Set3Components(0.0, 1.0, 0.0)
+	} else {
+		arcFrom.Set3Components(-q.Y/s, q.X/s, 0.0)
+	}
+
+	arcTo.Set3Components(
+		q.W*arcFrom.X-q.Z*arcFrom.Y,
+		q.W*arcFrom.Y+q.Z*arcFrom.X,
+		q.X*arcFrom.Y-q.Y*arcFrom.X)
+
+	if q.W < 0.0 {
+		arcFrom.ScaleBy(-1.0)
+	}
+}
+
+// nearestConstraintAxis returns the index of the axis whose great circle
+// passes closest to 'loose'.
+func (ab *ArcBall) nearestConstraintAxis(loose *smath.Vector3) int {
+	nearest := 0
+	maxDot := -1.0
+	var onPlane smath.Vector3
+	for i := range ab.axes {
+		onPlane.Set(loose)
+		constrainToAxis(&onPlane, &ab.axes[i])
+		d := onPlane.Dot(loose)
+		if d > maxDot {
+			maxDot = d
+			nearest = i
+		}
+	}
+	return nearest
+}
+
+// constrainToAxis projects 'loose' onto the great circle perpendicular to
+// 'axis', keeping it on the front of the ball.
+func constrainToAxis(loose, axis *smath.Vector3) {
+	d := axis.Dot(loose)
+	loose.Set3Components(loose.X-axis.X*d, loose.Y-axis.Y*d, loose.Z-axis.Z*d)
+
+	norm := loose.LengthSquared()
+	if norm > 0.0 {
+		if loose.Z < 0.0 {
+			loose.ScaleBy(-1.0)
+		}
+		loose.ScaleBy(1.0 / math.Sqrt(norm))
+		return
+	}
+
+	if axis.Z == 1.0 {
+		loose.Set3Components(1.0, 0.0, 0.0)
+	} else {
+		loose.Set3Components(-axis.Y, axis.X, 0.0)
+		loose.Normalize()
+	}
+}
+
 // GetRotationFromAxisAngle gets the arcball's rotation in radians.
 func (ab *ArcBall) GetRotationFromAxisAngle(aa *smath.AxisAngle) {
 	aa.SetFromQuaternion(&ab.qNow)
@@ -0,0 +1,154 @@
+package graphics
+
+import (
+	"SoftRenderer/smath"
+	"math"
+	"testing"
+)
+
+const tolerance = 1e-9
+
+// newTestBall returns a ball of radius 50 in the middle of a 200x200
+// canvas with +y downward like the mouse.
+func newTestBall() *ArcBall {
+	ab := NewArcBall()
+	ab.SetCanvasSize(200, 200)
+	ab.Place(&smath.Vector3{X: 100, Y: 100}, 50)
+	return ab
+}
+
+// drag moves the mouse to x0,y0, then drags to x1,y1 and returns the
+// resulting rotation.
+func drag(ab *ArcBall, x0, y0, x1, y1 float64) smath.Quaternion {
+	ab.Mouse(&smath.Vector3{X: x0, Y: y0})
+	ab.Update()
+	ab.BeginDrag()
+	ab.Mouse(&smath.Vector3{X: x1, Y: y1})
+	ab.Update()
+	return ab.Rotation()
+}
+
+func nearQuat(q smath.Quaternion, w, x, y, z float64) bool {
+	return math.Abs(q.W-w) < tolerance && math.Abs(q.X-x) < tolerance &&
+		math.Abs(q.Y-y) < tolerance && math.Abs(q.Z-z) < tolerance
+}
+
+func TestArcBallDrag(t *testing.T) {
+	// The sine and cosine of 45 degrees
+	s := math.Sqrt(0.5)
+	// A point 45 degrees around the ball from its center
+	off := 50 * s
+
+	tests := []struct {
+		name           string
+		x0, y0, x1, y1 float64
+		want           smath.Quaternion
+	}{
+		{"center to center", 100, 100, 100, 100, smath.Quaternion{W: 1}},
+		// An arc of 45 degrees rotates by 90
+		{"right about y", 100, 100, 100 + off, 100, smath.Quaternion{W: s, Y: s}},
+		// Screen y is downward, so up rotates about -x
+		{"up about x", 100, 100, 100, 100 - off, smath.Quaternion{W: s, X: -s}},
+		// The rim is 90 degrees from the center
+		{"to the rim", 100, 100, 150, 100, smath.Quaternion{Y: 1}},
+		// Beyond the ball is the same as the rim
+		{"outside", 100, 100, 400, 100, smath.Quaternion{Y: 1}},
+	}
+
+	for _, tt := range tests {
+		q := drag(newTestBall(), tt.x0, tt.y0, tt.x1, tt.y1)
+		if !nearQuat(q, tt.want.W, tt.want.X, tt.want.Y, tt.want.Z) {
+			t.Errorf("%s: rotation %v, want %v", tt.name, q, tt.want)
+		}
+	}
+}
+
+func TestArcBallMatrix(t *testing.T) {
+	ab := newTestBall()
+	m := ab.GetMatrix()
+	drag(ab, 100, 100, 100, 100)
+	for i := 0; i < 16; i++ {
+		want := 0.0
+		if i%5 == 0 {
+			want = 1.0
+		}
+		if math.Abs(m.C(i)-want) > tolerance {
+			t.Fatalf("center drag matrix isn't identity\n%v", m)
+		}
+	}
+
+	// 90 degrees about y turns +z into +x
+	ab = newTestBall()
+	drag(ab, 100, 100, 100+50*math.Sqrt(0.5), 100)
+	ab.EndDrag()
+	m = ab.GetMatrix()
+	if math.Abs(m.C(smath.M02)-1) > tolerance || math.Abs(m.C(smath.M22)) > tolerance {
+		t.Errorf("z column is %v,%v,%v, want 1,0,0", m.C(smath.M02), m.C(smath.M12), m.C(smath.M22))
+	}
+	if q := ab.Rotation(); !nearQuat(q, math.Sqrt(0.5), 0, math.Sqrt(0.5), 0) {
+		t.Errorf("EndDrag changed the rotation to %v", q)
+	}
+}
+
+func TestArcBallMouseOnSphere(t *testing.T) {
+	tests := []struct {
+		name string
+		x, y float64
+		want smath.Vector3
+	}{
+		{"center", 100, 100, smath.Vector3{Z: 1}},
+		{"rim", 100, 50, smath.Vector3{Y: 1}},
+		{"outside right", 400, 100, smath.Vector3{X: 1}},
+		{"outside diagonal", 0, 200, smath.Vector3{X: -math.Sqrt(0.5), Y: -math.Sqrt(0.5)}},
+	}
+
+	for _, tt := range tests {
+		ab := newTestBall()
+		ab.Mouse(&smath.Vector3{X: tt.x, Y: tt.y})
+		var v smath.Vector3
+		ab.mouseOnSphere(&ab.vNow, &v)
+		if math.Abs(v.X-tt.want.X) > tolerance || math.Abs(v.Y-tt.want.Y) > tolerance || math.Abs(v.Z-tt.want.Z) > tolerance {
+			t.Errorf("%s: on the ball at %v, want %v", tt.name, v, tt.want)
+		}
+		if l := v.LengthSquared(); math.Abs(l-1) > tolerance {
+			t.Errorf("%s: length² %v, want 1", tt.name, l)
+		}
+	}
+}
+
+func TestArcBallConstrainedDrag(t *testing.T) {
+	ab := newTestBall()
+	ab.UseSet(ArcBallCameraAxes)
+
+	// Hovering right of the center is nearest the y axis' circle
+	ab.Mouse(&smath.Vector3{X: 120, Y: 100})
+	ab.Update()
+	if ab.ConstraintAxis() != 1 {
+		t.Fatalf("constrained to axis %d, want 1", ab.ConstraintAxis())
+	}
+
+	// Dragging up and right only turns about y
+	q := drag(ab, 120, 100, 140, 70)
+	if math.Abs(q.X) > tolerance || math.Abs(q.Z) > tolerance {
+		t.Errorf("rotation %v isn't about y", q)
+	}
+	if q.Y <= 0 || q.W >= 1 {
+		t.Errorf("rotation %v didn't turn toward +x", q)
+	}
+
+	// The same drag unconstrained tilts about x too
+	free := drag(newTestBall(), 120, 100, 140, 70)
+	if math.Abs(free.X) < 0.1 {
+		t.Errorf("free rotation %v has no x component", free)
+	}
+
+	// The constraint holds for the whole drag
+	ab.Mouse(&smath.Vector3{X: 100, Y: 140})
+	ab.Update()
+	if ab.ConstraintAxis() != 1 {
+		t.Errorf("axis changed to %d mid drag", ab.ConstraintAxis())
+	}
+	if q := ab.Rotation(); math.Abs(q.X) > tolerance || math.Abs(q.Z) > tolerance {
+		t.Errorf("rotation %v isn't about y", q)
+	}
+}
@@ -0,0 +1,229 @@
+package graphics
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Glyph is a 1 bit bitmap of a character.
+type Glyph struct {
+	// Advance is how far the pen moves right after the glyph
+	Advance int
+
+	// The bitmap's box relative to the pen on the baseline, with +y up
+	// as in BDF. OffsetY is the bottom of the box, negative for
+	// descenders.
+	Width   int
+	Height  int
+	OffsetX int
+	OffsetY int
+
+	// Rows top to bottom, packed MSB first
+	bits   []byte
+	stride int
+}
+
+func newGlyph(width, height int) *Glyph {
+	g := new(Glyph)
+	g.Width = width
+	g.Height = height
+	g.stride = (width + 7) / 8
+	g.bits = make([]byte, g.stride*height)
+	return g
+}
+
+// IsSet returns true if the pixel at column x of row y, counting rows
+// from the top, is inked.
+func (g *Glyph) IsSet(x, y int) bool {
+	return g.bits[y*g.stride+(x>>3)]&(0x80>>uint(x&7)) != 0
+}
+
+func (g *Glyph) set(x, y int) {
+	g.bits[y*g.stride+(x>>3)] |= 0x80 >> uint(x&7)
+}
+
+// BitmapFont is a fixed size 1 bit font, for example, loaded from a BDF
+// file or the built in 5x7 font.
+type BitmapFont struct {
+	// Ascent and Descent are the pixels above and below the baseline
+	Ascent  int
+	Descent int
+
+	glyphs map[rune]*Glyph
+	// Drawn for runes the font doesn't have
+	fallback *Glyph
+}
+
+// LineHeight returns the distance between baselines.
+func (f *BitmapFont) LineHeight() int {
+	return f.Ascent + f.Descent
+}
+
+// Glyph returns the glyph for 'r', or the fallback if the font lacks it.
+func (f *BitmapFont) Glyph(r rune) *Glyph {
+	if g, ok := f.glyphs[r]; ok {
+		return g
+	}
+	return f.fallback
+}
+
+// LoadBDF loads a font from a BDF file.
+func LoadBDF(path string) (*BitmapFont, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	f, err := ParseBDF(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return f, nil
+}
+
+// ParseBDF reads a font in the Glyph Bitmap Distribution Format.
+func ParseBDF(r io.Reader) (*BitmapFont, error) {
+	f := &BitmapFont{glyphs: map[rune]*Glyph{}}
+
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	fail := func(format string, a ...interface{}) error {
+		return fmt.Errorf("bdf: line %d: %s", lineNo, fmt.Sprintf(format, a...))
+	}
+
+	// Font wide defaults
+	var boxH, boxYOff int
+	haveAscent := false
+	haveDescent := false
+	defaultChar := -1
+
+	// Current glyph
+	var encoding, advance int
+	var g *Glyph
+	bitmapRow := -1
+
+	for scanner.Scan() {
+		lineNo++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		if bitmapRow >= 0 && fields[0] != "ENDCHAR" {
+			if bitmapRow >= g.Height {
+				return nil, fail("more bitmap rows than BBX height %d", g.Height)
+			}
+			row, err := hex.DecodeString(fields[0])
+			if err != nil {
+				return nil, fail("bad bitmap row %q", fields[0])
+			}
+			copy(g.bits[bitmapRow*g.stride:(bitmapRow+1)*g.stride], row)
+			bitmapRow++
+			continue
+		}
+
+		ints, err := atois(fields[1:])
+		switch fields[0] {
+		case "FONTBOUNDINGBOX":
+			if err != nil || len(ints) != 4 {
+				return nil, fail("bad FONTBOUNDINGBOX")
+			}
+			boxH, boxYOff = ints[1], ints[3]
+		case "FONT_ASCENT":
+			if err != nil || len(ints) != 1 {
+				return nil, fail("bad FONT_ASCENT")
+			}
+			f.Ascent = ints[0]
+			haveAscent = true
+		case "FONT_DESCENT":
+			if err != nil || len(ints) != 1 {
+				return nil, fail("bad FONT_DESCENT")
+			}
+			f.Descent = ints[0]
+			haveDescent = true
+		case "DEFAULT_CHAR":
+			if err != nil || len(ints) != 1 {
+				return nil, fail("bad DEFAULT_CHAR")
+			}
+			defaultChar = ints[0]
+		case "STARTCHAR":
+			encoding = -1
+			advance = 0
+			g = nil
+		case "ENCODING":
+			// A second value is a non standard encoding, ignored.
+			if err != nil || len(ints) < 1 {
+				return nil, fail("bad ENCODING")
+			}
+			encoding = ints[0]
+		case "DWIDTH":
+			if err != nil || len(ints) != 2 {
+				return nil, fail("bad DWIDTH")
+			}
+			advance = ints[0]
+		case "BBX":
+			if err != nil || len(ints) != 4 || ints[0] < 0 || ints[1] < 0 {
+				return nil, fail("bad BBX")
+			}
+			g = newGlyph(ints[0], ints[1])
+			g.OffsetX = ints[2]
+			g.OffsetY = ints[3]
+		case "BITMAP":
+			if g == nil {
+				return nil, fail("BITMAP before BBX")
+			}
+			bitmapRow = 0
+		case "ENDCHAR":
+			if g == nil {
+				return nil, fail("ENDCHAR without BBX")
+			}
+			bitmapRow = -1
+			g.Advance = advance
+			// Glyphs without a standard encoding can't be looked up.
+			if encoding >= 0 {
+				f.glyphs[rune(encoding)] = g
+			}
+			g = nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(f.glyphs) == 0 {
+		return nil, fmt.Errorf("bdf: no glyphs")
+	}
+
+	if !haveAscent {
+		f.Ascent = boxH + boxYOff
+	}
+	if !haveDescent {
+		f.Descent = -boxYOff
+	}
+
+	f.fallback = f.glyphs[rune(defaultChar)]
+	if f.fallback == nil {
+		f.fallback = f.glyphs['?']
+	}
+	if f.fallback == nil {
+		f.fallback = newGlyph(0, 0)
+	}
+	return f, nil
+}
+
+func atois(fields []string) ([]int, error) {
+	ints := make([]int, len(fields))
+	for i, s := range fields {
+		v, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, err
+		}
+		ints[i] = v
+	}
+	return ints, nil
+}
@@ -0,0 +1,147 @@
+package graphics
+
+import "strings"
+
+// The built in font is 5x7 pixels with 2 pixel descenders in a 6x9 cell.
+const (
+	builtinAscent  = 7
+	builtinDescent = 2
+	builtinAdvance = 6
+)
+
+// Each glyph is its rows from the top, separated by spaces. Rows beyond
+// the seventh are below the baseline.
+var builtinGlyphs = [...]struct {
+	r    rune
+	rows string
+}{
+	{' ', "..... ..... ..... ..... ..... ..... ....."},
+	{'!', "..#.. ..#.. ..#.. ..#.. ..#.. ..... ..#.."},
+	{'"', ".#.#. .#.#. ..... ..... ..... ..... ....."},
+	{'#', ".#.#. .#.#. ##### .#.#. ##### .#.#. .#.#."},
+	{'$', "..#.. .#### #.#.. .###. ..#.# ####. ..#.."},
+	{'%', "##... ##..# ...#. ..#.. .#... #..## ...##"},
+	{'&', ".##.. #..#. #.#.. .#... #.#.# #..#. .##.#"},
+	{'\'', "..#.. ..#.. .#... ..... ..... ..... ....."},
+	{'(', "...#. ..#.. .#... .#... .#... ..#.. ...#."},
+	{')', ".#... ..#.. ...#. ...#. ...#. ..#.. .#..."},
+	{'*', "..... ..#.. #.#.# .###. #.#.# ..#.. ....."},
+	{'+', "..... ..#.. ..#.. ##### ..#.. ..#.. ....."},
+	{',', "..... ..... ..... ..... ..... .##.. .##.. ..#.. .#..."},
+	{'-', "..... ..... ..... ##### ..... ..... ....."},
+	{'.', "..... ..... ..... ..... ..... .##.. .##.."},
+	{'/', "..... ....# ...#. ..#.. .#... #.... ....."},
+	{'0', ".###. #...# #..## #.#.# ##..# #...# .###."},
+	{'1', "..#.. .##.. ..#.. ..#.. ..#.. ..#.. .###."},
+	{'2', ".###. #...# ....# ...#. ..#.. .#... #####"},
+	{'3', "##### ...#. ..#.. ...#. ....# #...# .###."},
+	{'4', "...#. ..##. .#.#. #..#. ##### ...#. ...#."},
+	{'5', "##### #.... ####. ....# ....# #...# .###."},
+	{'6', "..##. .#... #.... ####. #...# #...# .###."},
+	{'7', "##### ....# ...#. ..#.. .#... .#... .#..."},
+	{'8', ".###. #...# #...# .###. #...# #...# .###."},
+	{'9', ".###. #...# #...# .#### ....# ...#. .##.."},
+	{':', "..... .##.. .##.. ..... ..... .##.. .##.."},
+	{';', "..... .##.. .##.. ..... ..... .##.. .##.. ..#.. .#..."},
+	{'<', "...#. ..#.. .#... #.... .#... ..#.. ...#."},
+	{'=', "..... ..... ##### ..... ##### ..... ....."},
+	{'>', ".#... ..#.. ...#. ....# ...#. ..#.. .#..."},
+	{'?', ".###. #...# ....# ...#. ..#.. ..... ..#.."},
+	{'@', ".###. #...# ....# .##.# #.#.# #.#.# .###."},
+	{'A', ".###. #...# #...# ##### #...# #...# #...#"},
+	{'B', "####. #...# #...# ####. #...# #...# ####."},
+	{'C', ".###. #...# #.... #.... #.... #...# .###."},
+	{'D', "###.. #..#. #...# #...# #...# #..#. ###.."},
+	{'E', "##### #.... #.... ####. #.... #.... #####"},
+	{'F', "##### #.... #.... ####. #.... #.... #...."},
+	{'G', ".###. #...# #.... #.### #...# #...# .####"},
+	{'H', "#...# #...# #...# ##### #...# #...# #...#"},
+	{'I', ".###. ..#.. ..#.. ..#.. ..#.. ..#.. .###."},
+	{'J', "..### ...#. ...#. ...#. ...#. #..#. .##.."},
+	{'K', "#...# #..#. #.#.. ##... #.#.. #..#. #...#"},
+	{'L', "#.... #.... #.... #.... #.... #.... #####"},
+	{'M', "#...# ##.## #.#.# #.#.# #...# #...# #...#"},
+	{'N', "#...# #...# ##..# #.#.# #..## #...# #...#"},
+	{'O', ".###. #...# #...# #...# #...# #...# .###."},
+	{'P', "####. #...# #...# ####. #.... #.... #...."},
+	{'Q', ".###. #...# #...# #...# #.#.# #..#. .##.#"},
+	{'R', "####. #...# #...# ####. #.#.. #..#. #...#"},
+	{'S', ".#### #.... #.... .###. ....# ....# ####."},
+	{'T', "##### ..#.. ..#.. ..#.. ..#.. ..#.. ..#.."},
+	{'U', "#...# #...# #...# #...# #...# #...# .###."},
+	{'V', "#...# #...# #...# #...# #...# .#.#. ..#.."},
+	{'W', "#...# #...# #...# #.#.# #.#.# #.#.# .#.#."},
+	{'X', "#...# #...# .#.#. ..#.. .#.#. #...# #...#"},
+	{'Y', "#...# #...# .#.#. ..#.. ..#.. ..#.. ..#.."},
+	{'Z', "##### ....# ...#. ..#.. .#... #.... #####"},
+	{'[', ".###. .#... .#... .#... .#... .#... .###."},
+	{'\\', "..... #.... .#... ..#.. ...#. ....# ....."},
+	{']', ".###. ...#. ...#. ...#. ...#. ...#. .###."},
+	{'^', "..#.. .#.#. #...# ..... ..... ..... ....."},
+	{'_', "..... ..... ..... ..... ..... ..... #####"},
+	{'`', ".#... ..#.. ..... ..... ..... ..... ....."},
+	{'a', "..... ..... .###. ....# .#### #...# .####"},
+	{'b', "#.... #.... #.##. ##..# #...# #...# ####."},
+	{'c', "..... ..... .###. #.... #.... #...# .###."},
+	{'d', "....# ....# .##.# #..## #...# #...# .####"},
+	{'e', "..... ..... .###. #...# ##### #.... .###."},
+	{'f', "..##. .#..# .#... ###.. .#... .#... .#..."},
+	{'g', "..... ..... .#### #...# #...# #...# .#### ....# .###."},
+	{'h', "#.... #.... #.##. ##..# #...# #...# #...#"},
+	{'i', "..#.. ..... .##.. ..#.. ..#.. ..#.. .###."},
+	{'j', "...#. ..... ..##. ...#. ...#. ...#. ...#. #..#. .##.."},
+	{'k', "#.... #.... #..#. #.#.. ##... #.#.. #..#."},
+	{'l', ".##.. ..#.. ..#.. ..#.. ..#.. ..#.. .###."},
+	{'m', "..... ..... ##.#. #.#.# #.#.# #.#.# #.#.#"},
+	{'n', "..... ..... #.##. ##..# #...# #...# #...#"},
+	{'o', "..... ..... .###. #...# #...# #...# .###."},
+	{'p', "..... ..... ####. #...# #...# #...# ####. #.... #...."},
+	{'q', "..... ..... .#### #...# #...# #...# .#### ....# ....#"},
+	{'r', "..... ..... #.##. ##..# #.... #.... #...."},
+	{'s', "..... ..... .###. #.... .###. ....# ####."},
+	{'t', ".#... .#... ###.. .#... .#... .#..# ..##."},
+	{'u', "..... ..... #...# #...# #...# #..## .##.#"},
+	{'v', "..... ..... #...# #...# #...# .#.#. ..#.."},
+	{'w', "..... ..... #...# #...# #.#.# #.#.# .#.#."},
+	{'x', "..... ..... #...# .#.#. ..#.. .#.#. #...#"},
+	{'y', "..... ..... #...# #...# #...# #...# .#### ....# .###."},
+	{'z', "..... ..... ##### ...#. ..#.. .#... #####"},
+	{'{', "...#. ..#.. ..#.. .#... ..#.. ..#.. ...#."},
+	{'|', "..#.. ..#.. ..#.. ..#.. ..#.. ..#.. ..#.."},
+	{'}', ".#... ..#.. ..#.. ...#. ..#.. ..#.. .#..."},
+	{'~', "..... ..... .#... #.#.# ...#. ..... ....."},
+}
+
+var builtinFont *BitmapFont
+
+// BuiltinFont returns the built in 5x7 ASCII font. It is shared, so it
+// must not be modified.
+func BuiltinFont() *BitmapFont {
+	if builtinFont != nil {
+		return builtinFont
+	}
+
+	f := &BitmapFont{
+		Ascent:  builtinAscent,
+		Descent: builtinDescent,
+		glyphs:  make(map[rune]*Glyph, len(builtinGlyphs)),
+	}
+	for _, bg := range builtinGlyphs {
+		rows := strings.Fields(bg.rows)
+		g := newGlyph(len(rows[0]), len(rows))
+		g.Advance = builtinAdvance
+		g.OffsetY = builtinAscent - len(rows)
+		for y, row := range rows {
+			for x, c := range row {
+				if c == '#' {
+					g.set(x, y)
+				}
+			}
+		}
+		f.glyphs[bg.r] = g
+	}
+	f.fallback = f.glyphs['?']
+
+	builtinFont = f
+	return f
+}
@@ -0,0 +1,127 @@
+package graphics
+
+import (
+	"strings"
+	"testing"
+)
+
+const testBDF = `STARTFONT 2.1
+FONT -test-fixed-medium-r-normal--8-80-75-75-c-40-iso10646-1
+SIZE 8 75 75
+FONTBOUNDINGBOX 4 8 0 -2
+CHARS 2
+STARTCHAR A
+ENCODING 65
+DWIDTH 5 0
+BBX 4 6 0 0
+BITMAP
+60
+90
+90
+F0
+90
+90
+ENDCHAR
+STARTCHAR unencoded
+ENCODING -1
+DWIDTH 4 0
+BBX 1 1 0 0
+BITMAP
+80
+ENDCHAR
+ENDFONT
+`
+
+func TestParseBDF(t *testing.T) {
+	f, err := ParseBDF(strings.NewReader(testBDF))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Without FONT_ASCENT and FONT_DESCENT they come from the bounding box
+	if f.Ascent != 6 || f.Descent != 2 {
+		t.Errorf("ascent %d, descent %d; want 6, 2", f.Ascent, f.Descent)
+	}
+
+	g := f.Glyph('A')
+	if g.Advance != 5 || g.Width != 4 || g.Height != 6 {
+		t.Fatalf("A is %dx%d advancing %d, want 4x6 advancing 5", g.Width, g.Height, g.Advance)
+	}
+
+	want := []string{
+		".##.",
+		"#..#",
+		"#..#",
+		"####",
+		"#..#",
+		"#..#",
+	}
+	for y, row := range want {
+		for x, c := range row {
+			if g.IsSet(x, y) != (c == '#') {
+				t.Errorf("A pixel %d,%d set %v, want %v", x, y, g.IsSet(x, y), c == '#')
+			}
+		}
+	}
+
+	// Neither DEFAULT_CHAR nor '?' exist, so the fallback is empty
+	if fb := f.Glyph('B'); fb == g || fb.Width != 0 {
+		t.Errorf("missing rune gave a %dx%d glyph, want an empty fallback", fb.Width, fb.Height)
+	}
+}
+
+func TestParseBDFDefaultChar(t *testing.T) {
+	src := strings.Replace(testBDF, "CHARS 2", "FONT_ASCENT 7\nFONT_DESCENT 1\nDEFAULT_CHAR 65\nCHARS 2", 1)
+	f, err := ParseBDF(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if f.Ascent != 7 || f.Descent != 1 {
+		t.Errorf("ascent %d, descent %d; want 7, 1", f.Ascent, f.Descent)
+	}
+	if f.Glyph('Z') != f.Glyph('A') {
+		t.Error("missing rune didn't fall back to DEFAULT_CHAR")
+	}
+}
+
+func TestParseBDFErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		old  string
+		new  string
+		want string
+	}{
+		{"bad bounding box", "FONTBOUNDINGBOX 4 8 0 -2", "FONTBOUNDINGBOX 4 8", "line 4: bad FONTBOUNDINGBOX"},
+		{"bad ascent", "CHARS 2", "FONT_ASCENT x", "line 5: bad FONT_ASCENT"},
+		{"bad encoding", "ENCODING 65", "ENCODING", "line 7: bad ENCODING"},
+		{"bad dwidth", "DWIDTH 5 0", "DWIDTH 5", "line 8: bad DWIDTH"},
+		{"negative BBX", "BBX 4 6 0 0", "BBX 4 -6 0 0", "line 9: bad BBX"},
+		{"bitmap before BBX", "BBX 4 6 0 0\n", "", "line 9: BITMAP before BBX"},
+		{"bad row", "F0", "FG", "line 14: bad bitmap row"},
+		{"too many rows", "F0", "F0\nF0\nF0\nF0", "more bitmap rows than BBX height 6"},
+		// The other glyph is already unencoded
+		{"no glyphs", "ENCODING 65", "ENCODING -1", "no glyphs"},
+	}
+
+	for _, tt := range tests {
+		src := strings.Replace(testBDF, tt.old, tt.new, 1)
+		f, err := ParseBDF(strings.NewReader(src))
+		if err == nil {
+			t.Errorf("%s: parsed without error", tt.name)
+			continue
+		}
+		if f != nil {
+			t.Errorf("%s: returned a font with the error", tt.name)
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error %q, want it to contain %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestLoadBDFMissingFile(t *testing.T) {
+	if _, err := LoadBDF("testdata/does-not-exist.bdf"); err == nil {
+		t.Error("loaded a missing file")
+	}
+}
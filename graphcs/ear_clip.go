@@ -0,0 +1,143 @@
+package graphics
+
+import "errors"
+
+// EarClip triangulates a simple (non self-intersecting) polygon outline,
+// which may be concave, given as x,y pairs in either winding order.
+//
+// It returns three vertex indices per triangle along with three shared
+// flags per triangle. Edge 1 is v1->v2, edge 2 is v2->v3 and edge 3 is
+// v3->v1. An edge is shared when it is an internal diagonal that another
+// triangle also borders, otherwise it lies on the outline.
+//
+// Collinear vertices are dropped where that doesn't join an outline edge
+// to a diagonal, so there can be fewer than n-2 triangles. An outline
+// with no area is an error.
+func EarClip(points []int) (indices []int, shared []bool, err error) {
+	n := len(points) / 2
+	if n < 3 {
+		return nil, nil, errors.New("outline needs at least 3 vertices")
+	}
+
+	// Remaining vertices ordered so the signed area is positive
+	remain := make([]int, n)
+	area := 0
+	for i := 0; i < n; i++ {
+		j := (i + 1) % n
+		area += points[i*2]*points[j*2+1] - points[j*2]*points[i*2+1]
+		remain[i] = i
+	}
+	if area == 0 {
+		return nil, nil, errors.New("outline has no area")
+	}
+	if area < 0 {
+		for i, j := 0, n-1; i < j; i, j = i+1, j-1 {
+			remain[i], remain[j] = remain[j], remain[i]
+		}
+	}
+
+	// outer[k] is true if the edge from remain[k] to its successor
+	// is part of the original outline.
+	outer := make([]bool, n)
+	for i := range outer {
+		outer[i] = true
+	}
+
+	cross := func(a, b, c int) int {
+		return (points[b*2]-points[a*2])*(points[c*2+1]-points[a*2+1]) -
+			(points[b*2+1]-points[a*2+1])*(points[c*2]-points[a*2])
+	}
+
+	// clip removes remain[k] as the triangle of it and its neighbours
+	clip := func(k int) {
+		m := len(remain)
+		kp := (k + m - 1) % m
+		indices = append(indices, remain[kp], remain[k], remain[(k+1)%m])
+		shared = append(shared, !outer[kp], !outer[k], true)
+
+		// The edge from the previous to the next vertex is a new
+		// internal diagonal
+		outer[kp] = false
+		remain = append(remain[:k], remain[k+1:]...)
+		outer = append(outer[:k], outer[k+1:]...)
+	}
+
+	for len(remain) > 3 {
+		m := len(remain)
+		clipped := false
+		mixed := -1
+
+		for k := 0; k < m; k++ {
+			kp := (k + m - 1) % m
+			kn := (k + 1) % m
+			a := remain[kp]
+			b := remain[k]
+			c := remain[kn]
+
+			c0 := cross(a, b, c)
+			if c0 == 0 {
+				if outer[kp] != outer[k] {
+					// Dropping it would make a->c part outline and
+					// part diagonal, so clip a real ear first.
+					mixed = k
+					continue
+				}
+				// Collinear vertex, it adds no area so drop it.
+				remain = append(remain[:k], remain[k+1:]...)
+				outer = append(outer[:k], outer[k+1:]...)
+				clipped = true
+				break
+			}
+			if c0 < 0 {
+				// Reflex vertex
+				continue
+			}
+			if containsVertex(points, remain, a, b, c, cross) {
+				continue
+			}
+
+			clip(k)
+			clipped = true
+			break
+		}
+
+		if !clipped && mixed >= 0 {
+			// No ear is left, so clip the collinear vertex as a
+			// triangle with no area. Its edges keep their own flags.
+			clip(mixed)
+			clipped = true
+		}
+		if !clipped {
+			return nil, nil, errors.New("outline is not a simple polygon")
+		}
+	}
+
+	if cross(remain[0], remain[1], remain[2]) != 0 {
+		indices = append(indices, remain[0], remain[1], remain[2])
+		shared = append(shared, !outer[0], !outer[1], !outer[2])
+	}
+
+	return indices, shared, nil
+}
+
+// containsVertex checks if any remaining vertex, other than the ear's own,
+// is inside or on the ear a,b,c.
+func containsVertex(points, remain []int, a, b, c int, cross func(a, b, c int) int) bool {
+	for _, v := range remain {
+		if v == a || v == b || v == c {
+			continue
+		}
+		// Touching vertices at the same position don't block the ear.
+		if samePos(points, v, a) || samePos(points, v, b) || samePos(points, v, c) {
+			continue
+		}
+		if cross(a, b, v) >= 0 && cross(b, c, v) >= 0 && cross(c, a, v) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func samePos(points []int, i, j int) bool {
+	return points[i*2] == points[j*2] && points[i*2+1] == points[j*2+1]
+}
@@ -0,0 +1,163 @@
+package graphics
+
+import (
+	"math"
+	"testing"
+)
+
+// cross2 is twice the signed area of the triangle a,b,c
+func cross2(points []int, a, b, c int) int {
+	return (points[b*2]-points[a*2])*(points[c*2+1]-points[a*2+1]) -
+		(points[b*2+1]-points[a*2+1])*(points[c*2]-points[a*2])
+}
+
+// onOutline checks if a and b are joined by a run of outline edges
+// lying along the segment a->b. Collinear vertices may have been dropped
+// in between.
+func onOutline(points []int, a, b int) bool {
+	n := len(points) / 2
+	within := func(v int) bool {
+		return cross2(points, a, b, v) == 0 &&
+			(points[v*2]-points[a*2])*(points[v*2]-points[b*2]) <= 0 &&
+			(points[v*2+1]-points[a*2+1])*(points[v*2+1]-points[b*2+1]) <= 0
+	}
+	for _, step := range []int{1, n - 1} {
+		v := (a + step) % n
+		for v != b && within(v) {
+			v = (v + step) % n
+		}
+		if v == b {
+			return true
+		}
+	}
+	return false
+}
+
+func length(points []int, a, b int) float64 {
+	return math.Hypot(float64(points[b*2]-points[a*2]), float64(points[b*2+1]-points[a*2+1]))
+}
+
+func reversed(points []int) []int {
+	r := make([]int, 0, len(points))
+	for i := len(points) - 2; i >= 0; i -= 2 {
+		r = append(r, points[i], points[i+1])
+	}
+	return r
+}
+
+func TestEarClip(t *testing.T) {
+	lShape := []int{0, 0, 20, 0, 20, 10, 10, 10, 10, 20, 0, 20}
+
+	tests := []struct {
+		name      string
+		points    []int
+		triangles int
+		// Twice the outline's area
+		area2 int
+	}{
+		{"triangle", []int{0, 0, 10, 0, 0, 10}, 1, 100},
+		{"convex quad", []int{0, 0, 10, 0, 12, 10, 0, 8}, 2, 196},
+		{"concave L", lShape, 4, 600},
+		{"concave L reversed", reversed(lShape), 4, 600},
+		// The vertex midway along the bottom is dropped
+		{"collinear", []int{5, 0, 10, 0, 10, 10, 0, 10, 0, 0}, 2, 200},
+		// Clipping 1 leaves 0 between the diagonal 2->0 and the
+		// outline edge 0->4, which point the same way.
+		{"collinear with a diagonal", []int{2, 1, 0, 2, 2, 0, 4, 4, 2, 3}, 3, 8},
+	}
+
+	for _, tt := range tests {
+		indices, shared, err := EarClip(tt.points)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if len(indices) != tt.triangles*3 || len(shared) != len(indices) {
+			t.Errorf("%s: %d indices and %d flags, want %d", tt.name, len(indices), len(shared), tt.triangles*3)
+			continue
+		}
+
+		area2 := 0
+		outline := 0.0
+		for i := 0; i < len(indices); i += 3 {
+			a2 := cross2(tt.points, indices[i], indices[i+1], indices[i+2])
+			if a2 < 0 {
+				t.Errorf("%s: triangle %d is wound the other way", tt.name, i/3)
+			}
+			area2 += a2
+
+			for e := 0; e < 3; e++ {
+				a, b := indices[i+e], indices[i+(e+1)%3]
+				if shared[i+e] {
+					continue
+				}
+				if !onOutline(tt.points, a, b) {
+					t.Errorf("%s: edge %d->%d is marked outer but isn't on the outline", tt.name, a, b)
+				}
+				outline += length(tt.points, a, b)
+			}
+		}
+		if area2 != tt.area2 {
+			t.Errorf("%s: triangles cover %d, want %d", tt.name, area2, tt.area2)
+		}
+
+		perimeter := 0.0
+		n := len(tt.points) / 2
+		for i := 0; i < n; i++ {
+			perimeter += length(tt.points, i, (i+1)%n)
+		}
+		if math.Abs(outline-perimeter) > 1e-9 {
+			t.Errorf("%s: outer edges are %v long, want the perimeter %v", tt.name, outline, perimeter)
+		}
+	}
+}
+
+func TestEarClipErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []int
+	}{
+		{"too few", []int{0, 0, 10, 0}},
+		{"collinear", []int{0, 0, 5, 5, 10, 10, 20, 20}},
+		{"bowtie", []int{0, 0, 10, 10, 10, 0, 0, 10}},
+		{"self intersecting", []int{0, 20, 30, 0, 20, 0, 20, 30, 30, 20}},
+	}
+
+	for _, tt := range tests {
+		indices, shared, err := EarClip(tt.points)
+		if err == nil {
+			t.Errorf("%s: triangulated into %v", tt.name, indices)
+		}
+		if indices != nil || shared != nil {
+			t.Errorf("%s: returned triangles with the error", tt.name)
+		}
+	}
+}
+
+func TestPolygonTriangulateReplaces(t *testing.T) {
+	tests := []struct {
+		name      string
+		outline   [][2]int
+		ok        bool
+		triangles int
+	}{
+		{"square", [][2]int{{0, 0}, {10, 0}, {10, 10}, {0, 10}}, true, 2},
+		// A failed triangulation keeps the added triangle
+		{"self intersecting", [][2]int{{0, 20}, {30, 0}, {20, 0}, {20, 30}, {30, 20}}, false, 1},
+	}
+
+	for _, tt := range tests {
+		p := NewPolygon().(*Polygon)
+		p.AddTriangle(0, 0, 1, 0, 0, 1, false, false, false)
+		for _, v := range tt.outline {
+			p.AddVertex(v[0], v[1], 0.5)
+		}
+
+		if err := p.Triangulate(); (err == nil) != tt.ok {
+			t.Errorf("%s: error %v, want ok %v", tt.name, err, tt.ok)
+		}
+		if len(p.triangles) != tt.triangles {
+			t.Errorf("%s: %d triangles, want %d", tt.name, len(p.triangles), tt.triangles)
+		}
+	}
+}
@@ -51,6 +51,17 @@ func (t *Edge) Z2() float32 {
 	return t.zQ
 }
 
+// Z is the depth interpolated at the current step
+func (t *Edge) Z() float32 {
+	if t.yQ != t.yP {
+		return t.zP + (t.zQ-t.zP)*float32(t.y-t.yP)/float32(t.yQ-t.yP)
+	}
+	if t.xQ != t.xP {
+		return t.zP + (t.zQ-t.zP)*float32(t.x-t.xP)/float32(t.xQ-t.xP)
+	}
+	return t.zP
+}
+
 // Set the vertices of the edge
 func (t *Edge) Set(xP, yP, xQ, yQ int, zP, zQ float32) {
 	// Note: the larger Y value is at the "bottom" or lower on the display
@@ -0,0 +1,45 @@
+package graphics
+
+import (
+	"testing"
+	"time"
+)
+
+// reportPixelRate reports the throughput of a benchmark that touches
+// 'pixels' pixels per iteration over 'elapsed'.
+func reportPixelRate(b *testing.B, pixels int, elapsed time.Duration) {
+	b.ReportMetric(float64(pixels)*float64(b.N)/elapsed.Seconds(), "pixels/s")
+}
+
+func BenchmarkEdgeStep(b *testing.B) {
+	edges := []struct {
+		name           string
+		xP, yP, xQ, yQ int
+	}{
+		{"shallow", 0, 0, 639, 200},
+		{"steep", 0, 0, 200, 479},
+		{"steep-left", 200, 0, 0, 479},
+		{"vertical", 100, 0, 100, 479},
+	}
+
+	for _, e := range edges {
+		b.Run(e.name, func(b *testing.B) {
+			edge := NewEdge()
+
+			steps := 0
+			edge.Set(e.xP, e.yP, e.xQ, e.yQ, 1.0, 1.0)
+			for edge.Step() {
+				steps++
+			}
+
+			b.ResetTimer()
+			start := time.Now()
+			for n := 0; n < b.N; n++ {
+				edge.Set(e.xP, e.yP, e.xQ, e.yQ, 1.0, 1.0)
+				for edge.Step() {
+				}
+			}
+			reportPixelRate(b, steps+1, time.Since(start))
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package graphics
+
+import (
+	"SoftRenderer/smath"
+	"math"
+)
+
+// Pitch stops just short of straight up or down so the view never flips.
+const maxPitch = math.Pi/2.0 - 0.01
+
+// FlyCamera is a free flying camera steered with WASD style movement and
+// mouse-look. Speeds are per second so movement is independent of the
+// frame rate.
+type FlyCamera struct {
+	// Speed is the movement speed in units per second.
+	Speed float64
+	// Sensitivity is the look rate in radians per mouse pixel.
+	Sensitivity float64
+
+	position smath.Vector3
+	// A yaw of 0 looks down -Z, positive turns right.
+	yaw   float64
+	pitch float64
+
+	// Movement intent, each within [-1, 1]
+	forward float64
+	strafe  float64
+	rise    float64
+
+	direction smath.Vector3
+	right     smath.Vector3
+	target    smath.Vector3
+	up        smath.Vector3
+	view      smath.Matrix4
+}
+
+// NewFlyCamera creates a camera at the origin looking down -Z.
+func NewFlyCamera() *FlyCamera {
+	c := new(FlyCamera)
+	c.Speed = 5.0
+	c.Sensitivity = 0.003
+	c.up.Set3Components(0.0, 1.0, 0.0)
+	c.Update(0.0)
+	return c
+}
+
+// SetPosition places the camera.
+func (c *FlyCamera) SetPosition(x, y, z float64) {
+	c.position.Set3Components(x, y, z)
+}
+
+// Position returns the camera's location.
+func (c *FlyCamera) Position() *smath.Vector3 {
+	return &c.position
+}
+
+// SetOrientation sets the yaw and pitch in radians.
+func (c *FlyCamera) SetOrientation(yaw, pitch float64) {
+	c.yaw = yaw
+	c.pitch = math.Max(-maxPitch, math.Min(maxPitch, pitch))
+}
+
+// Move sets how the camera should move on the next Update. Each value is
+// within [-1, 1], for example, forward = 1 while W is held and -1 while S
+// is held. 'rise' moves along world up.
+func (c *FlyCamera) Move(forward, strafe, rise float64) {
+	c.forward = forward
+	c.strafe = strafe
+	c.rise = rise
+}
+
+// Look turns the camera by a mouse movement given in pixels, where +y is
+// downward.
+func (c *FlyCamera) Look(dx, dy float64) {
+	c.SetOrientation(c.yaw+dx*c.Sensitivity, c.pitch-dy*c.Sensitivity)
+}
+
+// Update moves the camera by 'dt' seconds and rebuilds the view.
+func (c *FlyCamera) Update(dt float64) {
+	cp := math.Cos(c.pitch)
+	c.direction.Set3Components(math.Sin(c.yaw)*cp, math.Sin(c.pitch), -math.Cos(c.yaw)*cp)
+	c.right.Set3Components(math.Cos(c.yaw), 0.0, math.Sin(c.yaw))
+
+	// Moving diagonally isn't faster than moving straight.
+	f, s, r := c.forward, c.strafe, c.rise
+	l := smath.Length(f, s, r)
+	if l > 1.0 {
+		f /= l
+		s /= l
+		r /= l
+	}
+
+	d := c.Speed * dt
+	c.position.MulAdd(&c.direction, f*d)
+	c.position.MulAdd(&c.right, s*d)
+	c.position.MulAdd(&c.up, r*d)
+
+	c.target.Set(&c.position)
+	c.target.Add(&c.direction)
+	c.view.SetToLookAt(&c.position, &c.target, &c.up)
+}
+
+// View returns the world to camera transform.
+func (c *FlyCamera) View() *smath.Matrix4 {
+	return &c.view
+}
@@ -0,0 +1,61 @@
+package graphics
+
+import (
+	"SoftRenderer/smath"
+	"math"
+	"testing"
+)
+
+func nearMatrix(a, b *smath.Matrix4) bool {
+	for i := 0; i < 16; i++ {
+		if math.Abs(a.C(i)-b.C(i)) > tolerance {
+			return false
+		}
+	}
+	return true
+}
+
+func nearVector(a, b smath.Vector3) bool {
+	return math.Abs(a.X-b.X) < tolerance && math.Abs(a.Y-b.Y) < tolerance && math.Abs(a.Z-b.Z) < tolerance
+}
+
+func TestFlyCameraFrameRate(t *testing.T) {
+	tests := []struct {
+		name                  string
+		forward, strafe, rise float64
+	}{
+		{"forward", 1, 0, 0},
+		{"diagonal", 1, -1, 0},
+		{"all", 0.5, 1, -0.3},
+	}
+
+	for _, tt := range tests {
+		var cams [2]*FlyCamera
+		for i := range cams {
+			c := NewFlyCamera()
+			c.SetPosition(1, 2, 3)
+			c.SetOrientation(0.7, 0.3)
+			c.Move(tt.forward, tt.strafe, tt.rise)
+			cams[i] = c
+		}
+
+		start := *cams[0].Position()
+		cams[0].Update(1.0)
+		cams[1].Update(0.5)
+		cams[1].Update(0.5)
+
+		one, two := cams[0].Position(), cams[1].Position()
+		if !nearVector(*one, *two) {
+			t.Errorf("%s: one step to %v, two half steps to %v", tt.name, *one, *two)
+		}
+		if !nearMatrix(cams[0].View(), cams[1].View()) {
+			t.Errorf("%s: views differ\n%v\n%v", tt.name, cams[0].View(), cams[1].View())
+		}
+
+		// Never faster than Speed, even diagonally
+		moved := smath.Length(one.X-start.X, one.Y-start.Y, one.Z-start.Z)
+		if moved > cams[0].Speed+tolerance {
+			t.Errorf("%s: moved %v in a second, faster than %v", tt.name, moved, cams[0].Speed)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package graphics
+
+import (
+	"SoftRenderer/smath"
+	"math"
+)
+
+// OrbitCamera circles a target point. Dragging orbits and pans, the
+// mouse wheel zooms. Zooming eases towards the requested distance at a
+// rate given per second so it is independent of the frame rate.
+type OrbitCamera struct {
+	// RotateSensitivity is the orbit rate in radians per mouse pixel.
+	RotateSensitivity float64
+	// PanSensitivity is the pan per mouse pixel as a fraction of distance.
+	PanSensitivity float64
+	// ZoomStep is the fraction of the distance one wheel notch zooms by.
+	ZoomStep float64
+	// Damping is how quickly, per second, the distance reaches the zoom
+	// goal. Zero snaps immediately.
+	Damping float64
+
+	MinDistance float64
+	MaxDistance float64
+
+	target smath.Vector3
+	// A yaw and pitch of 0 places the camera on +Z looking down -Z.
+	yaw          float64
+	pitch        float64
+	distance     float64
+	goalDistance float64
+
+	eye  smath.Vector3
+	up   smath.Vector3
+	view smath.Matrix4
+}
+
+// NewOrbitCamera creates a camera 5 units from the origin on +Z.
+func NewOrbitCamera() *OrbitCamera {
+	c := new(OrbitCamera)
+	c.RotateSensitivity = 0.01
+	c.PanSensitivity = 0.002
+	c.ZoomStep = 0.1
+	c.Damping = 12.0
+	c.MinDistance = 0.1
+	c.MaxDistance = 1000.0
+	c.up.Set3Components(0.0, 1.0, 0.0)
+	c.SetDistance(5.0)
+	c.Update(0.0)
+	return c
+}
+
+// SetTarget sets the point orbited around.
+func (c *OrbitCamera) SetTarget(x, y, z float64) {
+	c.target.Set3Components(x, y, z)
+}
+
+// Target returns the point orbited around.
+func (c *OrbitCamera) Target() *smath.Vector3 {
+	return &c.target
+}
+
+// SetDistance immediately moves the camera to 'd' from the target.
+func (c *OrbitCamera) SetDistance(d float64) {
+	c.distance = c.clampDistance(d)
+	c.goalDistance = c.distance
+}
+
+// Rotate orbits by a mouse movement given in pixels, where +y is downward.
+func (c *OrbitCamera) Rotate(dx, dy float64) {
+	c.yaw -= dx * c.RotateSensitivity
+	c.pitch += dy * c.RotateSensitivity
+	c.pitch = math.Max(-maxPitch, math.Min(maxPitch, c.pitch))
+}
+
+// Pan slides the target across the view so the scene follows the mouse.
+func (c *OrbitCamera) Pan(dx, dy float64) {
+	k := c.PanSensitivity * c.distance
+	// The first two rows of the view are the camera's right and up.
+	v := &c.view
+	rx, ry, rz := v.C(smath.M00), v.C(smath.M01), v.C(smath.M02)
+	ux, uy, uz := v.C(smath.M10), v.C(smath.M11), v.C(smath.M12)
+	c.target.Set3Components(
+		c.target.X-rx*dx*k+ux*dy*k,
+		c.target.Y-ry*dx*k+uy*dy*k,
+		c.target.Z-rz*dx*k+uz*dy*k)
+}
+
+// Zoom moves towards (positive) or away from the target by a number of
+// wheel notches.
+func (c *OrbitCamera) Zoom(notches float64) {
+	c.goalDistance = c.clampDistance(c.goalDistance * math.Pow(1.0-c.ZoomStep, notches))
+}
+
+// Update eases the zoom by 'dt' seconds and rebuilds the view.
+func (c *OrbitCamera) Update(dt float64) {
+	if c.Damping > 0.0 {
+		c.distance += (c.goalDistance - c.distance) * (1.0 - math.Exp(-c.Damping*dt))
+	} else {
+		c.distance = c.goalDistance
+	}
+
+	cp := math.Cos(c.pitch)
+	c.eye.Set3Components(math.Sin(c.yaw)*cp, math.Sin(c.pitch), math.Cos(c.yaw)*cp)
+	c.eye.ScaleBy(c.distance)
+	c.eye.Add(&c.target)
+
+	c.view.SetToLookAt(&c.eye, &c.target, &c.up)
+}
+
+// View returns the world to camera transform.
+func (c *OrbitCamera) View() *smath.Matrix4 {
+	return &c.view
+}
+
+func (c *OrbitCamera) clampDistance(d float64) float64 {
+	return math.Max(c.MinDistance, math.Min(c.MaxDistance, d))
+}
@@ -0,0 +1,50 @@
+package graphics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestOrbitCameraFrameRate(t *testing.T) {
+	tests := []struct {
+		name    string
+		damping float64
+		notches float64
+	}{
+		{"zoom in", 12, 3},
+		{"zoom out", 2, -5},
+		{"snap", 0, 3},
+	}
+
+	for _, tt := range tests {
+		var cams [2]*OrbitCamera
+		for i := range cams {
+			c := NewOrbitCamera()
+			c.Damping = tt.damping
+			c.SetTarget(1, 0, -2)
+			c.Rotate(40, -25)
+			c.Zoom(tt.notches)
+			cams[i] = c
+		}
+
+		goal := 5.0 * math.Pow(1.0-cams[0].ZoomStep, tt.notches)
+		want := goal
+		if tt.damping > 0 {
+			want += (5.0 - goal) * math.Exp(-tt.damping)
+		}
+
+		cams[0].Update(1.0)
+		cams[1].Update(0.5)
+		cams[1].Update(0.5)
+
+		if math.Abs(cams[0].distance-want) > tolerance {
+			t.Errorf("%s: distance %v after a second, want %v", tt.name, cams[0].distance, want)
+		}
+		if !nearVector(cams[0].eye, cams[1].eye) {
+			t.Errorf("%s: one step to %v, two half steps to %v", tt.name, cams[0].eye, cams[1].eye)
+		}
+		if !nearMatrix(cams[0].View(), cams[1].View()) {
+			t.Errorf("%s: views differ\n%v\n%v", tt.name, cams[0].View(), cams[1].View())
+		}
+	}
+}
@@ -6,28 +6,93 @@ import "SoftRenderer/api"
 // are shared with other triangles that represent the polygon.
 
 // Our polygon can only be defined as Convex meaning there can't
-// be any insets or cavities.
+// be any insets or cavities. Concave outlines can be filled with
+// a ScanlineFiller instead, or triangulated into a Polygon using
+// Triangulate.
 type Polygon struct {
-	vertices []int // Indices into vertex buffer
+	// Outline vertices added with AddVertex. Format is: x,y,x,y...
+	vertices []int
+	depths   []float32
+
+	triangles []polyTriangle
+
+	triangle api.ITriangle
 }
 
+// polyTriangle is a triangle of the polygon. Edge 1 is v1->v2, edge 2
+// is v2->v3 and edge 3 is v3->v1.
+type polyTriangle struct {
+	x1, y1, x2, y2, x3, y3 int
+	z1, z2, z3             float32
+	shared                 [3]bool
+}
+
+// NewPolygon creates an empty polygon
 func NewPolygon() api.IPolygon {
 	o := new(Polygon)
+	o.triangle = NewTriangle()
 	return o
 }
 
+// AddVertex appends a vertex to the polygon's outline. The outline
+// is turned into triangles by Triangulate.
 func (p *Polygon) AddVertex(x, y int, z float32) {
-
+	p.vertices = append(p.vertices, x, y)
+	p.depths = append(p.depths, z)
 }
 
+// AddTriangle adds a triangle directly. Edge 1 is v1->v2, edge 2 is
+// v2->v3 and edge 3 is v3->v1.
 func (p *Polygon) AddTriangle(x1, y1, x2, y2, x3, y3 int, sharedE1, sharedE2, sharedE3 bool) {
+	p.triangles = append(p.triangles, polyTriangle{
+		x1: x1, y1: y1, x2: x2, y2: y2, x3: x3, y3: y3,
+		shared: [3]bool{sharedE1, sharedE2, sharedE3},
+	})
+}
+
+// Triangulate ear clips the outline, which may be concave, into
+// triangles marking the internal diagonals as shared edges. The result
+// replaces all of the polygon's triangles, including any added with
+// AddTriangle. On error the triangles are left as they were.
+func (p *Polygon) Triangulate() error {
+	indices, shared, err := EarClip(p.vertices)
+	if err != nil {
+		return err
+	}
+
+	p.triangles = p.triangles[:0]
+	for t := 0; t < len(indices); t += 3 {
+		a, b, c := indices[t], indices[t+1], indices[t+2]
+		p.triangles = append(p.triangles, polyTriangle{
+			x1: p.vertices[a*2], y1: p.vertices[a*2+1], z1: p.depths[a],
+			x2: p.vertices[b*2], y2: p.vertices[b*2+1], z2: p.depths[b],
+			x3: p.vertices[c*2], y3: p.vertices[c*2+1], z3: p.depths[c],
+			shared: [3]bool{shared[t], shared[t+1], shared[t+2]},
+		})
+	}
 
+	return nil
 }
 
+// Draw renders the outer edges only
 func (p *Polygon) Draw(raster api.IRasterBuffer) {
-
+	for _, t := range p.triangles {
+		if !t.shared[0] {
+			raster.DrawLineAmmeraal(t.x1, t.y1, t.x2, t.y2, t.z1, t.z2)
+		}
+		if !t.shared[1] {
+			raster.DrawLineAmmeraal(t.x2, t.y2, t.x3, t.y3, t.z2, t.z3)
+		}
+		if !t.shared[2] {
+			raster.DrawLineAmmeraal(t.x3, t.y3, t.x1, t.y1, t.z3, t.z1)
+		}
+	}
 }
 
+// Fill renders each triangle filled
 func (p *Polygon) Fill(raster api.IRasterBuffer) {
-
+	for _, t := range p.triangles {
+		p.triangle.SetWithZ(t.x1, t.y1, t.z1, t.x2, t.y2, t.z2, t.x3, t.y3, t.z3)
+		p.triangle.Fill(raster)
+	}
 }
@@ -0,0 +1,190 @@
+package graphics
+
+import (
+	"SoftRenderer/api"
+	"math"
+	"sort"
+)
+
+// fillEdge is an entry in the edge table. Horizontal edges never cross a
+// scanline so they aren't entered.
+type fillEdge struct {
+	yTop, yBot float64 // yTop < yBot
+	xTop       float64 // x at yTop
+	dxdy       float64
+	winding    int // +1 if the edge runs downward, -1 if upward
+
+	// x at the current scanline
+	x float64
+}
+
+// ScanlineFiller fills outlines using an active edge table. Unlike
+// Triangle and Polygon the outline may be concave, self-intersecting and
+// made of several contours, for example, an outer boundary with holes.
+//
+// Coordinates are float pixel positions where integer values are pixel
+// centers. A pixel is filled if its center is inside the outline.
+type ScanlineFiller struct {
+	rule api.FillRule
+
+	// Edge table sorted by yTop
+	edges []fillEdge
+	// Active edge table, indices into edges
+	active []int
+
+	minY, maxY float64
+}
+
+// NewScanlineFiller creates an empty filler using the even-odd rule.
+func NewScanlineFiller() api.IScanlineFiller {
+	o := new(ScanlineFiller)
+	o.rule = api.FillEvenOdd
+	o.Reset()
+	return o
+}
+
+// SetFillRule selects even-odd or non-zero winding
+func (f *ScanlineFiller) SetFillRule(rule api.FillRule) {
+	f.rule = rule
+}
+
+// Reset removes all contours
+func (f *ScanlineFiller) Reset() {
+	f.edges = f.edges[:0]
+	f.active = f.active[:0]
+	f.minY = math.Inf(1)
+	f.maxY = math.Inf(-1)
+}
+
+// AddContour adds a closed contour given as x,y pairs. The last point
+// implicitly connects back to the first.
+func (f *ScanlineFiller) AddContour(points []float32) {
+	n := len(points) / 2
+	if n < 3 {
+		return
+	}
+
+	for i := 0; i < n; i++ {
+		j := (i + 1) % n
+		x0 := float64(points[i*2])
+		y0 := float64(points[i*2+1])
+		x1 := float64(points[j*2])
+		y1 := float64(points[j*2+1])
+
+		if y0 == y1 {
+			continue
+		}
+
+		e := fillEdge{winding: 1}
+		if y0 > y1 {
+			x0, x1 = x1, x0
+			y0, y1 = y1, y0
+			e.winding = -1
+		}
+		e.yTop = y0
+		e.yBot = y1
+		e.dxdy = (x1 - x0) / (y1 - y0)
+		e.xTop = x0
+
+		f.edges = append(f.edges, e)
+
+		f.minY = math.Min(f.minY, y0)
+		f.maxY = math.Max(f.maxY, y1)
+	}
+}
+
+// Fill rasterizes the contours into 'raster' at depth z using the
+// buffer's current pixel color.
+func (f *ScanlineFiller) Fill(raster api.IRasterBuffer, z float32) {
+	if len(f.edges) == 0 {
+		return
+	}
+
+	sort.Slice(f.edges, func(i, j int) bool {
+		return f.edges[i].yTop < f.edges[j].yTop
+	})
+
+	f.active = f.active[:0]
+	next := 0
+
+	// A row is sampled at its center, so an edge covers the rows
+	// whose y is within [yTop, yBot). Rows outside the raster are
+	// skipped; edges starting above it are picked up on row 0.
+	minY := math.Max(f.minY, 0)
+	maxY := math.Min(f.maxY, float64(raster.Pixels().Bounds().Dy()))
+	if !(minY < maxY) {
+		return
+	}
+	y0 := int(math.Ceil(minY))
+	y1 := int(math.Ceil(maxY)) - 1
+
+	for y := y0; y <= y1; y++ {
+		yc := float64(y)
+
+		// Move edges that start on or before this row into the AET
+		for next < len(f.edges) && f.edges[next].yTop <= yc {
+			f.active = append(f.active, next)
+			next++
+		}
+
+		// Retire finished edges and update x for the rest
+		k := 0
+		for _, i := range f.active {
+			e := &f.edges[i]
+			if e.yBot <= yc {
+				continue
+			}
+			e.x = e.xTop + (yc-e.yTop)*e.dxdy
+			f.active[k] = i
+			k++
+		}
+		f.active = f.active[:k]
+
+		// The AET is nearly sorted from the previous row, except where
+		// edges cross, so an insertion sort is cheap.
+		for i := 1; i < len(f.active); i++ {
+			for j := i; j > 0 && f.edges[f.active[j]].x < f.edges[f.active[j-1]].x; j-- {
+				f.active[j], f.active[j-1] = f.active[j-1], f.active[j]
+			}
+		}
+
+		f.fillRow(raster, y, z)
+	}
+}
+
+func (f *ScanlineFiller) fillRow(raster api.IRasterBuffer, y int, z float32) {
+	switch f.rule {
+	case api.FillNonZero:
+		// A span starts where the winding leaves zero and ends where
+		// it returns to zero.
+		winding := 0
+		xl := 0.0
+		for _, i := range f.active {
+			e := &f.edges[i]
+			w := winding
+			winding += e.winding
+			if w == 0 && winding != 0 {
+				xl = e.x
+			} else if w != 0 && winding == 0 {
+				f.fillSpan(raster, xl, e.x, y, z)
+			}
+		}
+	default:
+		// Spans are between consecutive pairs of crossings.
+		for n := 0; n+1 < len(f.active); n += 2 {
+			f.fillSpan(raster, f.edges[f.active[n]].x, f.edges[f.active[n+1]].x, y, z)
+		}
+	}
+}
+
+// fillSpan fills the pixels whose centers are within [xl, xr)
+func (f *ScanlineFiller) fillSpan(raster api.IRasterBuffer, xl, xr float64, y int, z float32) {
+	// Keep huge x within int range, FillSpan clips the rest
+	width := float64(raster.Pixels().Bounds().Dx())
+	xl = math.Max(xl, -1)
+	xr = math.Min(xr, width+1)
+	if !(xl < xr) {
+		return
+	}
+	raster.FillSpan(int(math.Ceil(xl)), int(math.Ceil(xr))-1, y, z, z)
+}
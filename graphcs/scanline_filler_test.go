@@ -0,0 +1,157 @@
+package graphics
+
+import (
+	"SoftRenderer/api"
+	"SoftRenderer/renderer"
+	"image/color"
+	"math"
+	"testing"
+	"time"
+)
+
+// fillContours fills the contours on a cleared 40x40 buffer and returns
+// it with the number of pixels drawn.
+func fillContours(rule api.FillRule, contours ...[]float32) (*renderer.RasterBuffer, int) {
+	rb := renderer.NewRasterBuffer(40, 40).(*renderer.RasterBuffer)
+	rb.Clear()
+	rb.SetPixelColor(color.RGBA{R: 255, A: 255})
+
+	f := NewScanlineFiller()
+	f.SetFillRule(rule)
+	for _, c := range contours {
+		f.AddContour(c)
+	}
+	f.Fill(rb, 0.5)
+
+	n := 0
+	pix := rb.Pixels()
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 40; x++ {
+			if pix.RGBAAt(x, y) != rb.ClearColor {
+				n++
+			}
+		}
+	}
+	return rb, n
+}
+
+// winding returns the winding number of the contour about x,y and the
+// number of edges a ray to the right crosses.
+func winding(points []float32, x, y float64) (w, crossings int) {
+	n := len(points) / 2
+	for i := 0; i < n; i++ {
+		j := (i + 1) % n
+		x0, y0 := float64(points[i*2]), float64(points[i*2+1])
+		x1, y1 := float64(points[j*2]), float64(points[j*2+1])
+		if (y0 <= y) == (y1 <= y) {
+			continue
+		}
+		if x0+(y-y0)*(x1-x0)/(y1-y0) <= x {
+			continue
+		}
+		crossings++
+		if y1 > y0 {
+			w++
+		} else {
+			w--
+		}
+	}
+	return w, crossings
+}
+
+func TestScanlineFillPentagram(t *testing.T) {
+	// Every second point of a regular pentagon, so the center is wound
+	// around twice.
+	var star []float32
+	for i := 0; i < 5; i++ {
+		a := -math.Pi/2 + float64(i)*4*math.Pi/5
+		star = append(star, float32(20+15*math.Cos(a)), float32(20+15*math.Sin(a)))
+	}
+
+	evenOdd, nonZero := 0, 0
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 40; x++ {
+			w, c := winding(star, float64(x), float64(y))
+			if c%2 == 1 {
+				evenOdd++
+			}
+			if w != 0 {
+				nonZero++
+			}
+		}
+	}
+
+	tests := []struct {
+		name   string
+		rule   api.FillRule
+		count  int
+		center bool
+	}{
+		{"even-odd", api.FillEvenOdd, evenOdd, false},
+		{"non-zero", api.FillNonZero, nonZero, true},
+	}
+
+	for _, tt := range tests {
+		rb, n := fillContours(tt.rule, star)
+		if n != tt.count {
+			t.Errorf("%s: %d pixels, want %d", tt.name, n, tt.count)
+		}
+		if filled := rb.Pixels().RGBAAt(20, 20) != rb.ClearColor; filled != tt.center {
+			t.Errorf("%s: center filled %v, want %v", tt.name, filled, tt.center)
+		}
+	}
+	if nonZero <= evenOdd {
+		t.Errorf("non-zero covers %d pixels, not more than even-odd's %d", nonZero, evenOdd)
+	}
+}
+
+func TestScanlineFillHole(t *testing.T) {
+	outer := []float32{10, 10, 30, 10, 30, 30, 10, 30}
+	reversed := []float32{15, 15, 15, 25, 25, 25, 25, 15}
+	same := []float32{15, 15, 25, 15, 25, 25, 15, 25}
+
+	tests := []struct {
+		name  string
+		rule  api.FillRule
+		hole  []float32
+		count int
+	}{
+		// Centers 10..29 less 15..24
+		{"even-odd", api.FillEvenOdd, reversed, 400 - 100},
+		{"even-odd same direction", api.FillEvenOdd, same, 400 - 100},
+		{"non-zero", api.FillNonZero, reversed, 400 - 100},
+		// Wound around twice, so it isn't a hole
+		{"non-zero same direction", api.FillNonZero, same, 400},
+	}
+
+	for _, tt := range tests {
+		rb, n := fillContours(tt.rule, outer, tt.hole)
+		if n != tt.count {
+			t.Errorf("%s: %d pixels, want %d", tt.name, n, tt.count)
+		}
+		if got := rb.DepthAt(12, 12); got != 0.5 {
+			t.Errorf("%s: depth %v, want 0.5", tt.name, got)
+		}
+	}
+}
+
+func TestScanlineFillHugeCoordinates(t *testing.T) {
+	contours := [][]float32{
+		{-1e9, -1e9, 1e9, -1e9, 1e9, 1e9, -1e9, 1e9},
+		{20, 20, 21, 1e30, 19, 1e30},
+		{0, 0, 10, float32(math.Inf(1)), 20, 0},
+		{0, 0, 10, float32(math.NaN()), 20, 0},
+	}
+
+	start := time.Now()
+	for i, c := range contours {
+		_, n := fillContours(api.FillNonZero, c)
+		if i == 0 && n != 40*40 {
+			t.Errorf("covering square filled %d pixels, want %d", n, 40*40)
+		}
+	}
+	// Rows outside the raster used to be walked one at a time
+	if d := time.Since(start); d > 2*time.Second {
+		t.Errorf("huge contours took %v", d)
+	}
+}
@@ -0,0 +1,158 @@
+package graphics
+
+import (
+	"SoftRenderer/api"
+	"image"
+	"image/color"
+	"strings"
+)
+
+// TextAlign positions lines horizontally relative to the x given to Draw
+type TextAlign int
+
+const (
+	// AlignLeft starts lines at x
+	AlignLeft TextAlign = iota
+	// AlignCenter centers lines on x
+	AlignCenter
+	// AlignRight ends lines at x
+	AlignRight
+)
+
+// TextRenderer draws strings with a BitmapFont directly into a raster
+// buffer, so text is part of the rendered frame. Lines are separated by
+// '\n' and each is aligned on its own.
+type TextRenderer struct {
+	Font  *BitmapFont
+	Color color.RGBA
+	Align TextAlign
+	// Scale is the integer size of each font pixel
+	Scale int
+	// Clip limits drawing to a rectangle. An empty rectangle clips to
+	// the raster only.
+	Clip image.Rectangle
+	// Z is the depth text is drawn at
+	Z float32
+}
+
+// NewTextRenderer creates a renderer drawing white, left aligned text at
+// scale 1. A nil font uses the built in font.
+func NewTextRenderer(font *BitmapFont) *TextRenderer {
+	if font == nil {
+		font = BuiltinFont()
+	}
+	t := new(TextRenderer)
+	t.Font = font
+	t.Color = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	t.Scale = 1
+	return t
+}
+
+// Measure returns the size of the text's box in pixels: the widest line
+// and the height of all lines.
+func (t *TextRenderer) Measure(text string) (width, height int) {
+	lines := strings.Split(text, "\n")
+	for _, line := range lines {
+		w := t.lineWidth(line)
+		if w > width {
+			width = w
+		}
+	}
+	return width, len(lines) * t.Font.LineHeight() * t.scale()
+}
+
+// Draw draws 'text' with the top of its first line at y.
+func (t *TextRenderer) Draw(raster api.IRasterBuffer, text string, x, y int) {
+	s := t.scale()
+
+	clip := raster.Pixels().Bounds()
+	if !t.Clip.Empty() {
+		clip = clip.Intersect(t.Clip)
+	}
+	if clip.Empty() {
+		return
+	}
+
+	raster.SetPixelColor(t.Color)
+
+	for _, line := range strings.Split(text, "\n") {
+		penX := x
+		switch t.Align {
+		case AlignCenter:
+			penX -= t.lineWidth(line) / 2
+		case AlignRight:
+			penX -= t.lineWidth(line)
+		}
+
+		// Skip lines entirely above or below the clip
+		baseline := y + t.Font.Ascent*s
+		if y < clip.Max.Y && y+t.Font.LineHeight()*s > clip.Min.Y {
+			for _, r := range line {
+				g := t.Font.Glyph(r)
+				t.drawGlyph(raster, g, penX, baseline, clip)
+				penX += g.Advance * s
+			}
+		}
+
+		y += t.Font.LineHeight() * s
+	}
+}
+
+// drawGlyph fills each run of inked pixels in a row as a single span.
+func (t *TextRenderer) drawGlyph(raster api.IRasterBuffer, g *Glyph, penX, baseline int, clip image.Rectangle) {
+	s := t.scale()
+	left := penX + g.OffsetX*s
+	top := baseline - (g.OffsetY+g.Height)*s
+
+	if left >= clip.Max.X || left+g.Width*s <= clip.Min.X ||
+		top >= clip.Max.Y || top+g.Height*s <= clip.Min.Y {
+		return
+	}
+
+	for row := 0; row < g.Height; row++ {
+		for col := 0; col < g.Width; {
+			if !g.IsSet(col, row) {
+				col++
+				continue
+			}
+			start := col
+			for col < g.Width && g.IsSet(col, row) {
+				col++
+			}
+
+			xl := left + start*s
+			xr := left + col*s - 1
+			if xl < clip.Min.X {
+				xl = clip.Min.X
+			}
+			if xr > clip.Max.X-1 {
+				xr = clip.Max.X - 1
+			}
+			if xl > xr {
+				continue
+			}
+
+			for sy := 0; sy < s; sy++ {
+				py := top + row*s + sy
+				if py >= clip.Min.Y && py < clip.Max.Y {
+					raster.FillSpan(xl, xr, py, t.Z, t.Z)
+				}
+			}
+		}
+	}
+}
+
+func (t *TextRenderer) lineWidth(line string) int {
+	w := 0
+	for _, r := range line {
+		w += t.Font.Glyph(r).Advance
+	}
+	return w * t.scale()
+}
+
+func (t *TextRenderer) scale() int {
+	if t.Scale < 1 {
+		return 1
+	}
+	return t.Scale
+}
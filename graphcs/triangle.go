@@ -3,6 +3,15 @@ package graphics
 import (
 	"SoftRenderer/api"
 	"image/color"
+	"math"
+)
+
+// Default depth bias. The constant keeps edges of a flat triangle off
+// its fill, and the slope covers the up to a pixel of difference in
+// how lines and spans interpolate depth.
+const (
+	defaultBiasConstant = 0.001
+	defaultBiasSlope    = 1.0
 )
 
 // Triangle is a single triangle without shared edges.
@@ -15,6 +24,14 @@ type Triangle struct {
 
 	// Edges used for rasterization.
 	leftEdge, rightEdge api.IEdge
+
+	// Wireframe overlay
+	edgeColor color.RGBA
+	// Depth bias (aka polygon offset) added to the edges so they sit
+	// in front of the triangle's own fill. The final bias is:
+	// biasConstant + biasSlope * max(|dz/dx|, |dz/dy|)
+	biasConstant float32
+	biasSlope    float32
 }
 
 // NewTriangle creates a new triangle
@@ -22,6 +39,9 @@ func NewTriangle() api.ITriangle {
 	o := new(Triangle)
 	o.leftEdge = NewEdge()
 	o.rightEdge = NewEdge()
+	o.edgeColor = color.RGBA{R: 0, G: 0, B: 0, A: 255}
+	o.biasConstant = defaultBiasConstant
+	o.biasSlope = defaultBiasSlope
 	return o
 }
 
@@ -43,6 +63,19 @@ func (t *Triangle) SetWithZ(x1, y1 int, z1 float32, x2, y2 int, z2 float32, x3,
 	t.z3 = z3
 }
 
+// SetDepthBias sets the constant and slope scaled depth bias applied
+// to the edges when rendering a wireframe. NewTriangle starts with
+// a constant of 0.001 and a slope of 1.
+func (t *Triangle) SetDepthBias(constant, slope float32) {
+	t.biasConstant = constant
+	t.biasSlope = slope
+}
+
+// SetEdgeColor sets the wireframe color
+func (t *Triangle) SetEdgeColor(c color.RGBA) {
+	t.edgeColor = c
+}
+
 // Draw renders an outline
 func (t *Triangle) Draw(raster api.IRasterBuffer) {
 	t.sort()
@@ -60,20 +93,19 @@ func (t *Triangle) Draw(raster api.IRasterBuffer) {
 	} else {
 		// General case
 		// split the triangle into two triangles: top-half and bottom-half
-		x := int(float32(t.x1) + (float32(t.y2-t.y1)/float32(t.y3-t.y1))*float32(t.x3-t.x1))
-		// Do we need to find z too????
+		x, z := t.split()
 
 		// Top triangle
 		// flat-bottom
 		raster.DrawLineAmmeraal(t.x1, t.y1, t.x2, t.y2, t.z1, t.z2) // Right
-		raster.DrawLineAmmeraal(t.x2, t.y2, x, t.y2, t.z2, t.z2)    // Bottom
-		raster.DrawLineAmmeraal(t.x1, t.y1, x, t.y2, t.z1, t.z2)    // Left
+		raster.DrawLineAmmeraal(t.x2, t.y2, x, t.y2, t.z2, z)       // Bottom
+		raster.DrawLineAmmeraal(t.x1, t.y1, x, t.y2, t.z1, z)       // Left
 
 		// Bottom triangle
 		// flat-top
 		raster.DrawLineAmmeraal(t.x2, t.y2, t.x3, t.y3, t.z2, t.z3) // Left
-		raster.DrawLineAmmeraal(t.x2, t.y2, x, t.y2, t.z2, t.z2)    // Top
-		raster.DrawLineAmmeraal(x, t.y2, t.x3, t.y3, t.z2, t.z3)    // Right
+		raster.DrawLineAmmeraal(t.x2, t.y2, x, t.y2, t.z2, z)       // Top
+		raster.DrawLineAmmeraal(x, t.y2, t.x3, t.y3, z, t.z3)       // Right
 	}
 }
 
@@ -82,86 +114,146 @@ func (t *Triangle) Fill(raster api.IRasterBuffer) {
 	t.sort()
 
 	// Draw horizontals between left/right edges.
-	// raster.SetPixelColor(color.RGBA{R: 255, G: 255, B: 255, A: 255})
 
 	if t.y2 == t.y3 {
 		// Case for flat-bottom triangle
 		t.rightEdge.Set(t.x1, t.y1, t.x2, t.y2, t.z1, t.z2)
 		t.leftEdge.Set(t.x1, t.y1, t.x3, t.y3, t.z1, t.z3)
 		raster.FillTriangleAmmeraal(t.leftEdge, t.rightEdge, true, false)
-		// raster.DrawLine(t.x2, t.y2, t.x3, t.y3, 1.0, 1.0) // Bottom
 	} else if t.y1 == t.y2 {
 		// Case for flat-top triangle
 		t.leftEdge.Set(t.x1, t.y1, t.x3, t.y3, t.z1, t.z3)
 		t.rightEdge.Set(t.x2, t.y2, t.x3, t.y3, t.z2, t.z3)
 		raster.FillTriangleAmmeraal(t.leftEdge, t.rightEdge, false, false)
-		// raster.DrawLine(t.x1, t.y1, t.x2, t.y2, 1.0, 1.0) // Top
 	} else {
 		// General case:
 		// Split the triangle into two triangles: top-half and bottom-half
-		x := int(float32(t.x1) + (float32(t.y2-t.y1)/float32(t.y3-t.y1))*float32(t.x3-t.x1)) // x intercept
+		x, z := t.split() // x intercept
 
 		// --------------------------
 		// Top triangle flat-bottom
 		// We don't want to render the bottom edge because the flat-top triangle will render it.
 		// y2 will always be in the "middle" which means it is always at the bottom of the flat-bottom
 		// We also do render the right edge if it is shared with another triangle.
-		t.rightEdge.Set(t.x1, t.y1, t.x2, t.y2, 1.0, 1.0)
-		t.leftEdge.Set(t.x1, t.y1, x, t.y2, 1.0, 1.0)
-		raster.SetPixelColor(color.RGBA{R: 255, G: 255, B: 255, A: 255})
+		t.rightEdge.Set(t.x1, t.y1, t.x2, t.y2, t.z1, t.z2)
+		t.leftEdge.Set(t.x1, t.y1, x, t.y2, t.z1, z)
 		raster.FillTriangleAmmeraal(t.leftEdge, t.rightEdge, true, false)
 
-		// raster.SetPixelColor(color.RGBA{R: 0, G: 255, B: 0, A: 255})
-		// raster.DrawLineAmmeraal(t.x1, t.y1, t.x2, t.y2, 2.0, 2.0) // Right
-		// raster.DrawLineAmmeraal(t.x2, t.y2, x, t.y2, 2.0, 2.0) // Bottom
-		// raster.DrawLineAmmeraal(t.x1, t.y1, x, t.y2, 2.0, 2.0)    // Left
-
 		// --------------------------
 		// Bottom triangle flat-top
-		t.leftEdge.Set(x, t.y2, t.x3, t.y3, 2.0, 2.0)
-		t.rightEdge.Set(t.x2, t.y2, t.x3, t.y3, 2.0, 2.0)
-		// raster.SetPixelColor(color.RGBA{R: 255, G: 0, B: 255, A: 64})
-		raster.SetPixelColor(color.RGBA{R: 255, G: 255, B: 255, A: 255})
+		t.leftEdge.Set(x, t.y2, t.x3, t.y3, z, t.z3)
+		t.rightEdge.Set(t.x2, t.y2, t.x3, t.y3, t.z2, t.z3)
 		raster.FillTriangleAmmeraal(t.leftEdge, t.rightEdge, false, false)
+	}
+}
 
-		// raster.SetPixelColor(color.RGBA{R: 255, G: 0, B: 0, A: 255})
-		// raster.DrawLineAmmeraal(t.x2, t.y2, t.x3, t.y3, 2.0, 2.0) // Left
-		// raster.DrawLineAmmeraal(t.x2, t.y2, x, t.y2, 2.0, 2.0) // Top
-		// raster.DrawLineAmmeraal(x, t.y2, t.x3, t.y3, 2.0, 2.0)    // Right
+// Render rasterizes the triangle according to 'mode'. The fill uses the
+// buffer's current pixel color and the edges use the edge color.
+func (t *Triangle) Render(raster api.IRasterBuffer, mode api.RenderMode) {
+	switch mode {
+	case api.RenderFill:
+		t.Fill(raster)
+	case api.RenderDepth:
+		colorWrite := raster.ColorWriteEnabled()
+		raster.EnableColorWrite(false)
+		t.Fill(raster)
+		raster.EnableColorWrite(colorWrite)
+	case api.RenderWireframe:
+		t.drawEdges(raster)
+	case api.RenderFillWireframe:
+		t.Fill(raster)
+		t.drawEdges(raster)
+	case api.RenderHiddenLine:
+		colorWrite := raster.ColorWriteEnabled()
+		raster.EnableColorWrite(false)
+		t.Fill(raster)
+		raster.EnableColorWrite(colorWrite)
+		t.drawEdges(raster)
 	}
 }
 
+// drawEdges draws the three outer edges, without the internal split
+// line, pulled toward the viewer by the depth bias.
+func (t *Triangle) drawEdges(raster api.IRasterBuffer) {
+	bias := t.depthBias()
+
+	c := raster.GetPixelColor()
+	raster.SetPixelColor(t.edgeColor)
+
+	raster.DrawLineAmmeraal(t.x1, t.y1, t.x2, t.y2, t.z1+bias, t.z2+bias)
+	raster.DrawLineAmmeraal(t.x2, t.y2, t.x3, t.y3, t.z2+bias, t.z3+bias)
+	raster.DrawLineAmmeraal(t.x3, t.y3, t.x1, t.y1, t.z3+bias, t.z1+bias)
+
+	raster.SetPixelColor(c)
+}
+
+// depthBias computes the polygon offset. Larger z values are closer so the
+// bias is added. The slope is the triangle's maximum depth gradient in
+// screen space.
+func (t *Triangle) depthBias() float32 {
+	area := float32((t.x2-t.x1)*(t.y3-t.y1) - (t.x3-t.x1)*(t.y2-t.y1))
+	if area == 0 {
+		return t.biasConstant
+	}
+
+	dzdx := ((t.z2-t.z1)*float32(t.y3-t.y1) - (t.z3-t.z1)*float32(t.y2-t.y1)) / area
+	dzdy := ((t.z3-t.z1)*float32(t.x2-t.x1) - (t.z2-t.z1)*float32(t.x3-t.x1)) / area
+
+	m := math.Max(math.Abs(float64(dzdx)), math.Abs(float64(dzdy)))
+
+	return t.biasConstant + t.biasSlope*float32(m)
+}
+
+// split returns the x intercept and depth on the long edge (v1 -> v3) at
+// the middle vertex's scanline. The triangle must be sorted.
+func (t *Triangle) split() (x int, z float32) {
+	f := float32(t.y2-t.y1) / float32(t.y3-t.y1)
+	x = int(float32(t.x1) + f*float32(t.x3-t.x1))
+	z = t.z1 + f*(t.z3-t.z1)
+	return x, z
+}
+
 func (t *Triangle) sort() {
 	x := 0
 	y := 0
+	z := float32(0.0)
 
 	// Make y1 <= y2 if needed
 	if t.y1 > t.y2 {
 		x = t.x1
 		y = t.y1
+		z = t.z1
 		t.x1 = t.x2
 		t.y1 = t.y2
+		t.z1 = t.z2
 		t.x2 = x
 		t.y2 = y
+		t.z2 = z
 	}
 
 	// Now y1 <= y2. Make y1 <= y3
 	if t.y1 > t.y3 {
 		x = t.x1
 		y = t.y1
+		z = t.z1
 		t.x1 = t.x3
 		t.y1 = t.y3
+		t.z1 = t.z3
 		t.x3 = x
 		t.y3 = y
+		t.z3 = z
 	}
 
 	// Now y1 <= y2 and y1 <= y3. Make y2 <= y3
 	if t.y2 > t.y3 {
 		x = t.x2
 		y = t.y2
+		z = t.z2
 		t.x2 = t.x3
 		t.y2 = t.y3
+		t.z2 = t.z3
 		t.x3 = x
 		t.y3 = y
+		t.z3 = z
 	}
 }
@@ -0,0 +1,162 @@
+package graphics
+
+import (
+	"SoftRenderer/api"
+	"SoftRenderer/renderer"
+	"image/color"
+	"testing"
+	"time"
+)
+
+func BenchmarkTriangleFill(b *testing.B) {
+	triangles := []struct {
+		name                   string
+		x1, y1, x2, y2, x3, y3 int
+	}{
+		{"small", 100, 100, 110, 108, 104, 116},
+		{"large", 10, 10, 630, 200, 120, 470},
+		{"sliver", 5, 5, 635, 12, 634, 18},
+		{"flat-bottom", 100, 400, 500, 400, 300, 20},
+		{"flat-top", 100, 20, 500, 20, 300, 400},
+	}
+
+	for _, tr := range triangles {
+		b.Run(tr.name, func(b *testing.B) {
+			rb := renderer.NewRasterBuffer(640, 480).(*renderer.RasterBuffer)
+			tri := NewTriangle()
+
+			// Count the pixels covered by a single fill.
+			rb.Clear()
+			tri.Set(tr.x1, tr.y1, tr.x2, tr.y2, tr.x3, tr.y3)
+			tri.Fill(rb)
+			pixels := 0
+			pix := rb.Pixels()
+			for y := 0; y < 480; y++ {
+				for x := 0; x < 640; x++ {
+					if pix.RGBAAt(x, y) != rb.ClearColor {
+						pixels++
+					}
+				}
+			}
+
+			// The depth buffer is cleared outside of the measured time
+			// otherwise every fill after the first is depth rejected.
+			var elapsed time.Duration
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				b.StopTimer()
+				rb.ClearDepthBuffer()
+				tri.Set(tr.x1, tr.y1, tr.x2, tr.y2, tr.x3, tr.y3)
+				b.StartTimer()
+
+				start := time.Now()
+				tri.Fill(rb)
+				elapsed += time.Since(start)
+			}
+			reportPixelRate(b, pixels, elapsed)
+		})
+	}
+}
+
+var (
+	fillColor = color.RGBA{R: 255, A: 255}
+	edgeColor = color.RGBA{G: 255, A: 255}
+)
+
+// renderTriangle renders a flat triangle at depth 0.5 into a fresh
+// 40x40 raster and counts the fill and edge colored pixels.
+func renderTriangle(mode api.RenderMode, bias *[2]float32) (rb *renderer.RasterBuffer, fill, edge int) {
+	rb = renderer.NewRasterBuffer(40, 40).(*renderer.RasterBuffer)
+	rb.Clear()
+	rb.SetPixelColor(fillColor)
+
+	tri := NewTriangle()
+	if bias != nil {
+		tri.SetDepthBias(bias[0], bias[1])
+	}
+	tri.SetEdgeColor(edgeColor)
+	tri.SetWithZ(5, 5, 0.5, 35, 8, 0.5, 12, 34, 0.5)
+	tri.Render(rb, mode)
+
+	pix := rb.Pixels()
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 40; x++ {
+			switch pix.RGBAAt(x, y) {
+			case fillColor:
+				fill++
+			case edgeColor:
+				edge++
+			}
+		}
+	}
+	return rb, fill, edge
+}
+
+func TestTriangleRenderModes(t *testing.T) {
+	_, _, outline := renderTriangle(api.RenderWireframe, nil)
+	if outline == 0 {
+		t.Fatal("wireframe drew no edges")
+	}
+
+	tests := []struct {
+		mode       api.RenderMode
+		fill, edge bool
+	}{
+		{api.RenderFill, true, false},
+		{api.RenderDepth, false, false},
+		{api.RenderWireframe, false, true},
+		{api.RenderFillWireframe, true, true},
+		{api.RenderHiddenLine, false, true},
+	}
+
+	for _, tt := range tests {
+		rb, fill, edge := renderTriangle(tt.mode, nil)
+		if (fill > 0) != tt.fill {
+			t.Errorf("mode %v: %d fill pixels, want fill %v", tt.mode, fill, tt.fill)
+		}
+		if (edge > 0) != tt.edge {
+			t.Errorf("mode %v: %d edge pixels, want edges %v", tt.mode, edge, tt.edge)
+		}
+		// With the default bias no edge pixel loses to the fill
+		if tt.edge && edge != outline {
+			t.Errorf("mode %v: %d edge pixels, want the full outline of %d", tt.mode, edge, outline)
+		}
+		// Every mode but wireframe lays down depth
+		if tt.mode != api.RenderWireframe && rb.DepthAt(17, 15) != 0.5 {
+			t.Errorf("mode %v: depth %v inside the triangle, want 0.5", tt.mode, rb.DepthAt(17, 15))
+		}
+	}
+}
+
+func TestTriangleDepthBias(t *testing.T) {
+	_, _, outline := renderTriangle(api.RenderWireframe, nil)
+
+	// Without a bias the edges tie with the fill and are rejected
+	_, _, edge := renderTriangle(api.RenderFillWireframe, &[2]float32{0, 0})
+	if edge >= outline {
+		t.Errorf("unbiased edges drew %d of %d pixels, want some rejected", edge, outline)
+	}
+
+	// A flat triangle has no slope, so only the constant is added
+	rb, _, _ := renderTriangle(api.RenderFillWireframe, &[2]float32{0.25, 100})
+	if z := rb.DepthAt(5, 5); z != 0.75 {
+		t.Errorf("edge depth %v, want 0.75", z)
+	}
+}
+
+func TestTriangleRenderRestoresColorWrite(t *testing.T) {
+	for _, mode := range []api.RenderMode{api.RenderFill, api.RenderDepth, api.RenderWireframe, api.RenderFillWireframe, api.RenderHiddenLine} {
+		for _, enabled := range []bool{true, false} {
+			rb := renderer.NewRasterBuffer(40, 40)
+			rb.EnableColorWrite(enabled)
+
+			tri := NewTriangle()
+			tri.SetWithZ(5, 5, 0.5, 35, 8, 0.5, 12, 34, 0.5)
+			tri.Render(rb, mode)
+
+			if rb.ColorWriteEnabled() != enabled {
+				t.Errorf("mode %v changed color writes from %v", mode, enabled)
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package imaging
+
+import (
+	"bufio"
+	"encoding/binary"
+	"image"
+	"io"
+)
+
+const (
+	bmpFileHeaderSize = 14
+	bmpInfoHeaderSize = 40
+)
+
+// EncodeBMP writes img as an uncompressed 24 bit Windows bitmap.
+// Alpha is dropped.
+func EncodeBMP(w io.Writer, img image.Image) error {
+	src := toNRGBA(img)
+	width, height := src.Rect.Dx(), src.Rect.Dy()
+
+	// Rows are padded to a multiple of 4 bytes
+	stride := (width*3 + 3) &^ 3
+	offset := bmpFileHeaderSize + bmpInfoHeaderSize
+	size := offset + stride*height
+
+	bw := bufio.NewWriter(w)
+
+	header := make([]byte, offset)
+	header[0], header[1] = 'B', 'M'
+	binary.LittleEndian.PutUint32(header[2:], uint32(size))
+	binary.LittleEndian.PutUint32(header[10:], uint32(offset))
+
+	info := header[bmpFileHeaderSize:]
+	binary.LittleEndian.PutUint32(info[0:], bmpInfoHeaderSize)
+	binary.LittleEndian.PutUint32(info[4:], uint32(width))
+	// A positive height stores rows bottom to top
+	binary.LittleEndian.PutUint32(info[8:], uint32(height))
+	binary.LittleEndian.PutUint16(info[12:], 1)
+	binary.LittleEndian.PutUint16(info[14:], 24)
+	binary.LittleEndian.PutUint32(info[20:], uint32(stride*height))
+	// 72 DPI in pixels per meter
+	binary.LittleEndian.PutUint32(info[24:], 2835)
+	binary.LittleEndian.PutUint32(info[28:], 2835)
+	bw.Write(header)
+
+	row := make([]byte, stride)
+	for y := height - 1; y >= 0; y-- {
+		pix := src.Pix[y*src.Stride:]
+		for x := 0; x < width; x++ {
+			row[x*3], row[x*3+1], row[x*3+2] = pix[x*4+2], pix[x*4+1], pix[x*4]
+		}
+		bw.Write(row)
+	}
+
+	return bw.Flush()
+}
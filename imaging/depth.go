@@ -0,0 +1,69 @@
+package imaging
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"strings"
+)
+
+// EncodeDepthRaw writes depth as little endian float32 values in
+// row-major order, with no header.
+func EncodeDepthRaw(w io.Writer, depth []float32) error {
+	bw := bufio.NewWriter(w)
+
+	buf := make([]byte, 4)
+	for _, z := range depth {
+		binary.LittleEndian.PutUint32(buf, math.Float32bits(z))
+		bw.Write(buf)
+	}
+
+	return bw.Flush()
+}
+
+// EncodePFM writes a width x height row-major depth buffer as a
+// grayscale portable float map. PFM stores rows bottom to top.
+func EncodePFM(w io.Writer, depth []float32, width, height int) error {
+	if len(depth) != width*height {
+		return fmt.Errorf("pfm: %d values for a %dx%d image", len(depth), width, height)
+	}
+
+	bw := bufio.NewWriter(w)
+
+	// A negative scale marks the data little endian
+	fmt.Fprintf(bw, "Pf\n%d %d\n-1.0\n", width, height)
+
+	buf := make([]byte, 4)
+	for y := height - 1; y >= 0; y-- {
+		for _, z := range depth[y*width : (y+1)*width] {
+			binary.LittleEndian.PutUint32(buf, math.Float32bits(z))
+			bw.Write(buf)
+		}
+	}
+
+	return bw.Flush()
+}
+
+// SaveDepth writes a width x height depth buffer to path. The
+// extension picks the format: pfm, or raw and f32 for headerless
+// float32.
+func SaveDepth(path string, depth []float32, width, height int) error {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".pfm":
+		return create(path, func(w io.Writer) error {
+			return EncodePFM(w, depth, width, height)
+		})
+	case ".raw", ".f32":
+		if len(depth) != width*height {
+			return fmt.Errorf("imaging: %d depth values for a %dx%d image", len(depth), width, height)
+		}
+		return create(path, func(w io.Writer) error {
+			return EncodeDepthRaw(w, depth)
+		})
+	default:
+		return fmt.Errorf("imaging: unsupported depth format %q", ext)
+	}
+}
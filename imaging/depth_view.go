@@ -0,0 +1,167 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// DepthRamp picks the colors depth is shown with.
+type DepthRamp int
+
+const (
+	// RampGray shows near as white and far as black
+	RampGray DepthRamp = iota
+	// RampColor shows near as red through yellow and green to far blue
+	RampColor
+)
+
+// colorRamp is evenly spaced stops from far to near.
+var colorRamp = [...]color.RGBA{
+	{R: 48, G: 18, B: 59, A: 255},
+	{R: 40, G: 120, B: 230, A: 255},
+	{R: 30, G: 210, B: 180, A: 255},
+	{R: 120, G: 250, B: 80, A: 255},
+	{R: 240, G: 200, B: 40, A: 255},
+	{R: 240, G: 90, B: 20, A: 255},
+	{R: 122, G: 4, B: 3, A: 255},
+}
+
+// DepthView maps a depth buffer to colors. Depth follows the raster's
+// convention that larger values are closer.
+type DepthView struct {
+	Ramp DepthRamp
+	// Clear is the value of pixels nothing was drawn to. They are
+	// shown as Background and left out of the auto range.
+	Clear      float32
+	Background color.RGBA
+	// AutoRange stretches the ramp over the drawn pixels' depths.
+	// Otherwise it spans Min to Max.
+	AutoRange bool
+	Min, Max  float32
+	// Linearize treats values as perspective depth, 1/w, and shows
+	// them by distance instead. Perspective depth crowds most of a
+	// scene at the far end of the ramp.
+	Linearize bool
+}
+
+// NewDepthView creates an auto ranged grayscale view of buffers
+// cleared to clear.
+func NewDepthView(clear float32) *DepthView {
+	v := new(DepthView)
+	v.Clear = clear
+	v.Background = color.RGBA{A: 255}
+	v.AutoRange = true
+	return v
+}
+
+// value returns z in the space the ramp is applied in, where larger
+// is still closer.
+func (v *DepthView) value(z float32) float64 {
+	if v.Linearize {
+		return -1.0 / float64(z)
+	}
+	return float64(z)
+}
+
+// drawn reports if z was written to and can be shown.
+func (v *DepthView) drawn(z float32) bool {
+	if z == v.Clear || math.IsNaN(float64(z)) || math.IsInf(float64(z), 0) {
+		return false
+	}
+	return !v.Linearize || z != 0
+}
+
+// Range returns the range the ramp spans for depth, in linearized
+// units if Linearize is set. ok is false if nothing was drawn.
+func (v *DepthView) Range(depth []float32) (lo, hi float64, ok bool) {
+	if !v.AutoRange {
+		return v.value(v.Min), v.value(v.Max), true
+	}
+
+	lo, hi = math.Inf(1), math.Inf(-1)
+	for _, z := range depth {
+		if !v.drawn(z) {
+			continue
+		}
+		d := v.value(z)
+		if d < lo {
+			lo = d
+		}
+		if d > hi {
+			hi = d
+		}
+	}
+
+	return lo, hi, lo <= hi
+}
+
+// Draw maps depth, row-major and the size of dst, into dst.
+func (v *DepthView) Draw(dst *image.RGBA, depth []float32) {
+	width := dst.Rect.Dx()
+	height := dst.Rect.Dy()
+	if len(depth) < width*height {
+		return
+	}
+
+	lo, hi, ok := v.Range(depth)
+	scale := 0.0
+	if ok && hi > lo {
+		scale = 1.0 / (hi - lo)
+	}
+
+	for y := 0; y < height; y++ {
+		i := dst.PixOffset(dst.Rect.Min.X, dst.Rect.Min.Y+y)
+		for _, z := range depth[y*width : (y+1)*width] {
+			c := v.Background
+			if ok && v.drawn(z) {
+				t := 1.0
+				if scale > 0.0 {
+					t = (v.value(z) - lo) * scale
+				}
+				c = v.ramp(t)
+			}
+
+			dst.Pix[i] = c.R
+			dst.Pix[i+1] = c.G
+			dst.Pix[i+2] = c.B
+			dst.Pix[i+3] = c.A
+			i += 4
+		}
+	}
+}
+
+// Image returns a width x height visualization of depth.
+func (v *DepthView) Image(depth []float32, width, height int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	v.Draw(dst, depth)
+	return dst
+}
+
+// ramp returns the color for t, from 0 far to 1 near.
+func (v *DepthView) ramp(t float64) color.RGBA {
+	if t < 0.0 {
+		t = 0.0
+	} else if t > 1.0 {
+		t = 1.0
+	}
+
+	if v.Ramp == RampGray {
+		g := uint8(t*255.0 + 0.5)
+		return color.RGBA{R: g, G: g, B: g, A: 255}
+	}
+
+	f := t * float64(len(colorRamp)-1)
+	i := int(f)
+	if i >= len(colorRamp)-1 {
+		return colorRamp[len(colorRamp)-1]
+	}
+	f -= float64(i)
+
+	a, b := colorRamp[i], colorRamp[i+1]
+	lerp := func(p, q uint8) uint8 {
+		return uint8(float64(p) + (float64(q)-float64(p))*f + 0.5)
+	}
+
+	return color.RGBA{R: lerp(a.R, b.R), G: lerp(a.G, b.G), B: lerp(a.B, b.B), A: 255}
+}
@@ -0,0 +1,72 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Encoder writes an image in one file format.
+type Encoder func(w io.Writer, img image.Image) error
+
+// encoders by file extension
+var encoders = map[string]Encoder{
+	".png": png.Encode,
+	".ppm": EncodePPM,
+	".pam": EncodePAM,
+	".tga": EncodeTGA,
+	".bmp": EncodeBMP,
+}
+
+// EncoderFor returns the encoder for path's extension: png, ppm,
+// pam, tga or bmp.
+func EncoderFor(path string) (Encoder, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	enc, ok := encoders[ext]
+	if !ok {
+		return nil, fmt.Errorf("imaging: unsupported image format %q", ext)
+	}
+	return enc, nil
+}
+
+// Save writes img to path in the format given by its extension.
+func Save(path string, img image.Image) error {
+	enc, err := EncoderFor(path)
+	if err != nil {
+		return err
+	}
+
+	return create(path, func(w io.Writer) error {
+		return enc(w, img)
+	})
+}
+
+// create writes a file with write, reporting the first error.
+func create(path string, write func(w io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	err = write(f)
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// toNRGBA returns img as non premultiplied RGBA with its origin at
+// 0,0, which is what the file formats store.
+func toNRGBA(img image.Image) *image.NRGBA {
+	b := img.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Rect, img, b.Min, draw.Src)
+	return dst
+}
@@ -0,0 +1,244 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// testImage returns a 3x2 image whose origin isn't 0,0. Every pixel is
+// distinct so misplaced rows or channels show up.
+func testImage() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(5, 7, 8, 9))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			img.SetNRGBA(5+x, 7+y, pixel(x, y))
+		}
+	}
+	return img
+}
+
+func pixel(x, y int) color.NRGBA {
+	return color.NRGBA{R: uint8(10 + x), G: uint8(20 + y), B: uint8(30 + x + y), A: uint8(200 + 10*x + y)}
+}
+
+func encode(t *testing.T, enc Encoder) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := enc(&buf, testImage()); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestEncodePPM(t *testing.T) {
+	got := encode(t, EncodePPM)
+
+	header := "P6\n3 2\n255\n"
+	if !bytes.HasPrefix(got, []byte(header)) {
+		t.Fatalf("header %q, want %q", got[:len(header)], header)
+	}
+
+	want := []byte(header)
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			p := pixel(x, y)
+			want = append(want, p.R, p.G, p.B)
+		}
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("got\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestEncodePAM(t *testing.T) {
+	got := encode(t, EncodePAM)
+
+	want := []byte("P7\nWIDTH 3\nHEIGHT 2\nDEPTH 4\nMAXVAL 255\nTUPLTYPE RGB_ALPHA\nENDHDR\n")
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			p := pixel(x, y)
+			want = append(want, p.R, p.G, p.B, p.A)
+		}
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("got\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestEncodeTGA(t *testing.T) {
+	got := encode(t, EncodeTGA)
+	if len(got) != 18+3*2*4 {
+		t.Fatalf("%d bytes, want %d", len(got), 18+3*2*4)
+	}
+
+	h := got[:18]
+	for _, f := range []struct {
+		name      string
+		got, want int
+	}{
+		{"id length", int(h[0]), 0},
+		{"color map", int(h[1]), 0},
+		{"image type", int(h[2]), 2},
+		{"width", int(binary.LittleEndian.Uint16(h[12:])), 3},
+		{"height", int(binary.LittleEndian.Uint16(h[14:])), 2},
+		{"bits per pixel", int(h[16]), 32},
+		{"descriptor", int(h[17]), 0x28},
+	} {
+		if f.got != f.want {
+			t.Errorf("%s %d, want %d", f.name, f.got, f.want)
+		}
+	}
+
+	// Top to bottom, BGRA
+	var want []byte
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			p := pixel(x, y)
+			want = append(want, p.B, p.G, p.R, p.A)
+		}
+	}
+	if !bytes.Equal(got[18:], want) {
+		t.Errorf("pixels\n%v\nwant\n%v", got[18:], want)
+	}
+}
+
+func TestEncodeTGATooLarge(t *testing.T) {
+	var buf bytes.Buffer
+	img := image.NewNRGBA(image.Rect(0, 0, 0x10000, 1))
+	if EncodeTGA(&buf, img) == nil {
+		t.Error("encoded a TGA wider than 65535")
+	}
+}
+
+func TestEncodeBMP(t *testing.T) {
+	got := encode(t, EncodeBMP)
+
+	// 3 pixels of 3 bytes padded to 12
+	const stride = 12
+	const offset = 54
+	if len(got) != offset+stride*2 {
+		t.Fatalf("%d bytes, want %d", len(got), offset+stride*2)
+	}
+
+	le32 := func(i int) int { return int(binary.LittleEndian.Uint32(got[i:])) }
+	le16 := func(i int) int { return int(binary.LittleEndian.Uint16(got[i:])) }
+	for _, f := range []struct {
+		name      string
+		got, want int
+	}{
+		{"signature", le16(0), 'B' | 'M'<<8},
+		{"file size", le32(2), offset + stride*2},
+		{"pixel offset", le32(10), offset},
+		{"info size", le32(14), 40},
+		{"width", le32(18), 3},
+		{"height", le32(22), 2},
+		{"planes", le16(26), 1},
+		{"bits per pixel", le16(28), 24},
+		{"compression", le32(30), 0},
+		{"image size", le32(34), stride * 2},
+	} {
+		if f.got != f.want {
+			t.Errorf("%s %d, want %d", f.name, f.got, f.want)
+		}
+	}
+
+	// Bottom to top, BGR, zero padded
+	var want []byte
+	for y := 1; y >= 0; y-- {
+		for x := 0; x < 3; x++ {
+			p := pixel(x, y)
+			want = append(want, p.B, p.G, p.R)
+		}
+		want = append(want, 0, 0, 0)
+	}
+	if !bytes.Equal(got[offset:], want) {
+		t.Errorf("pixels\n%v\nwant\n%v", got[offset:], want)
+	}
+}
+
+func TestEncoderFor(t *testing.T) {
+	tests := []struct {
+		path string
+		ok   bool
+	}{
+		{"shot.png", true},
+		{"shot.PPM", true},
+		{"dir.d/shot.pam", true},
+		{"shot.tga", true},
+		{"shot.bmp", true},
+		{"shot.jpg", false},
+		{"shot", false},
+	}
+
+	for _, tt := range tests {
+		enc, err := EncoderFor(tt.path)
+		if (err == nil) != tt.ok || (enc != nil) != tt.ok {
+			t.Errorf("EncoderFor(%q) = %v, %v; want ok %v", tt.path, enc != nil, err, tt.ok)
+		}
+	}
+}
+
+func testDepth() []float32 {
+	return []float32{
+		1, 2, 3,
+		-0.5, float32(math.Inf(1)), 1e-8,
+	}
+}
+
+func TestEncodeDepthRaw(t *testing.T) {
+	var buf bytes.Buffer
+	depth := testDepth()
+	if err := EncodeDepthRaw(&buf, depth); err != nil {
+		t.Fatal(err)
+	}
+
+	got := buf.Bytes()
+	if len(got) != len(depth)*4 {
+		t.Fatalf("%d bytes, want %d", len(got), len(depth)*4)
+	}
+	for i, z := range depth {
+		if v := math.Float32frombits(binary.LittleEndian.Uint32(got[i*4:])); v != z {
+			t.Errorf("value %d is %v, want %v", i, v, z)
+		}
+	}
+}
+
+func TestEncodePFM(t *testing.T) {
+	var buf bytes.Buffer
+	depth := testDepth()
+	if err := EncodePFM(&buf, depth, 3, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	header := "Pf\n3 2\n-1.0\n"
+	got := buf.Bytes()
+	if !bytes.HasPrefix(got, []byte(header)) {
+		t.Fatalf("header %q, want %q", got[:len(header)], header)
+	}
+	got = got[len(header):]
+	if len(got) != len(depth)*4 {
+		t.Fatalf("%d bytes of data, want %d", len(got), len(depth)*4)
+	}
+
+	// Rows bottom to top
+	want := append(append([]float32{}, depth[3:]...), depth[:3]...)
+	for i, z := range want {
+		if v := math.Float32frombits(binary.LittleEndian.Uint32(got[i*4:])); v != z {
+			t.Errorf("value %d is %v, want %v", i, v, z)
+		}
+	}
+}
+
+func TestEncodePFMSizeMismatch(t *testing.T) {
+	var buf bytes.Buffer
+	if EncodePFM(&buf, testDepth(), 2, 2) == nil {
+		t.Error("encoded 6 values as a 2x2 map")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("wrote %d bytes before failing", buf.Len())
+	}
+}
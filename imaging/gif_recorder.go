@@ -0,0 +1,206 @@
+package imaging
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"io"
+	"math"
+)
+
+// GIF delays are in hundredths of a second and most viewers treat
+// delays under 2 as 10, so that is the shortest a frame is shown.
+const minGIFDelay = 2
+
+// GIFRecorder collects frames and writes them as an animated GIF.
+// Frames are kept in full color until Encode so a palette can be
+// shared across the whole animation.
+type GIFRecorder struct {
+	// Colors is the palette size, 2 to 256
+	Colors int
+	// PerFramePalette quantizes each frame on its own instead of
+	// sharing one palette. Better color, larger files.
+	PerFramePalette bool
+	// Dither applies Floyd-Steinberg error diffusion
+	Dither bool
+	// FrameRate is the most frames per second kept. Frames arriving
+	// sooner extend the previous frame instead.
+	FrameRate float64
+	// LoopCount is 0 to loop forever, -1 to play once or n to repeat
+	// n times.
+	LoopCount int
+	// MaxFrames is the most frames kept, 0 for no limit. Frames are
+	// kept in full color, 1.2MB each at 640x480, so a recording left
+	// running needs a limit. Frames past it are dropped.
+	MaxFrames int
+
+	frames  []*image.RGBA
+	starts  []float64
+	elapsed float64
+}
+
+// NewGIFRecorder creates a recorder for a shared 256 color palette
+// without dithering at 25 frames per second, limited to 250 frames.
+func NewGIFRecorder() *GIFRecorder {
+	r := new(GIFRecorder)
+	r.Colors = 256
+	r.FrameRate = 25.0
+	r.MaxFrames = 250
+	return r
+}
+
+// AddFrame appends a copy of img shown for duration seconds. Once Full
+// frames are dropped and don't add to the duration.
+func (r *GIFRecorder) AddFrame(img image.Image, duration float64) {
+	start := r.elapsed
+
+	n := len(r.starts)
+	if n > 0 && r.FrameRate > 0.0 && start-r.starts[n-1] < 1.0/r.FrameRate {
+		r.elapsed += duration
+		return
+	}
+
+	if r.Full() {
+		return
+	}
+	r.elapsed += duration
+
+	b := img.Bounds()
+	frame := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(frame, frame.Rect, img, b.Min, draw.Src)
+
+	r.frames = append(r.frames, frame)
+	r.starts = append(r.starts, start)
+}
+
+// Full reports if MaxFrames frames are kept.
+func (r *GIFRecorder) Full() bool {
+	return r.MaxFrames > 0 && len(r.frames) >= r.MaxFrames
+}
+
+// Len returns the number of frames kept.
+func (r *GIFRecorder) Len() int {
+	return len(r.frames)
+}
+
+// Duration returns the recorded time in seconds.
+func (r *GIFRecorder) Duration() float64 {
+	return r.elapsed
+}
+
+// Reset discards all frames.
+func (r *GIFRecorder) Reset() {
+	r.frames = nil
+	r.starts = nil
+	r.elapsed = 0.0
+}
+
+// delays converts frame start times to GIF delays. Each frame's
+// delay is the difference of rounded start times, so rounding
+// doesn't accumulate and the animation keeps its length.
+func (r *GIFRecorder) delays() []int {
+	delays := make([]int, len(r.starts))
+	for i, start := range r.starts {
+		end := r.elapsed
+		if i+1 < len(r.starts) {
+			end = r.starts[i+1]
+		}
+
+		d := int(math.Round(end*100.0)) - int(math.Round(start*100.0))
+		if d < minGIFDelay {
+			d = minGIFDelay
+		}
+		delays[i] = d
+	}
+	return delays
+}
+
+// Encode quantizes the frames and writes the animation to w.
+func (r *GIFRecorder) Encode(w io.Writer) error {
+	if len(r.frames) == 0 {
+		return errors.New("gif: no frames recorded")
+	}
+
+	colors := r.Colors
+	if colors < 2 || colors > 256 {
+		return errors.New("gif: Colors must be from 2 to 256")
+	}
+
+	var shared color.Palette
+	if !r.PerFramePalette {
+		h := NewHistogram()
+		for _, f := range r.frames {
+			h.Add(f)
+		}
+		shared = h.MedianCut(colors)
+	}
+
+	anim := &gif.GIF{
+		Image:     make([]*image.Paletted, len(r.frames)),
+		Delay:     r.delays(),
+		LoopCount: r.LoopCount,
+	}
+
+	for i, f := range r.frames {
+		palette := shared
+		if palette == nil {
+			h := NewHistogram()
+			h.Add(f)
+			palette = h.MedianCut(colors)
+		}
+		anim.Image[i] = r.quantize(f, palette)
+	}
+
+	return gif.EncodeAll(w, anim)
+}
+
+// Save encodes the animation to a file.
+func (r *GIFRecorder) Save(path string) error {
+	return create(path, r.Encode)
+}
+
+// quantize maps img onto palette. Without dithering exact colors are
+// cached, since rendered frames reuse few colors.
+func (r *GIFRecorder) quantize(img *image.RGBA, palette color.Palette) *image.Paletted {
+	dst := image.NewPaletted(img.Rect, palette)
+
+	if r.Dither {
+		draw.FloydSteinberg.Draw(dst, img.Rect, opaque{img}, image.Point{})
+		return dst
+	}
+
+	cache := make(map[uint32]uint8)
+	for y := 0; y < img.Rect.Dy(); y++ {
+		si := y * img.Stride
+		di := y * dst.Stride
+		for x := 0; x < img.Rect.Dx(); x++ {
+			p := img.Pix[si : si+3 : si+3]
+			key := uint32(p[0])<<16 | uint32(p[1])<<8 | uint32(p[2])
+
+			index, ok := cache[key]
+			if !ok {
+				index = uint8(palette.Index(color.RGBA{R: p[0], G: p[1], B: p[2], A: 255}))
+				cache[key] = index
+			}
+			dst.Pix[di+x] = index
+
+			si += 4
+		}
+	}
+
+	return dst
+}
+
+// opaque reads an RGBA image with alpha forced to 255, matching how
+// the raster is displayed.
+type opaque struct {
+	*image.RGBA
+}
+
+func (o opaque) At(x, y int) color.Color {
+	c := o.RGBAAt(x, y)
+	c.A = 255
+	return c
+}
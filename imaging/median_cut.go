@@ -0,0 +1,174 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"sort"
+)
+
+// Colors are binned at 6 bits per channel while building the
+// histogram. Each bin keeps the sum of its exact colors so the
+// palette entries are still accurate averages.
+const binShift = 2
+
+type colorBin struct {
+	r, g, b uint64
+	n       uint64
+}
+
+// mean returns the bin's average color.
+func (cb *colorBin) mean() [3]uint8 {
+	return [3]uint8{uint8(cb.r / cb.n), uint8(cb.g / cb.n), uint8(cb.b / cb.n)}
+}
+
+// Histogram counts the colors of one or more images for MedianCut.
+// Alpha is ignored.
+type Histogram struct {
+	bins map[uint32]*colorBin
+}
+
+// NewHistogram creates an empty Histogram.
+func NewHistogram() *Histogram {
+	h := new(Histogram)
+	h.bins = make(map[uint32]*colorBin)
+	return h
+}
+
+// Add counts every pixel of img.
+func (h *Histogram) Add(img *image.RGBA) {
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		i := img.PixOffset(b.Min.X, y)
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r, g, bl := img.Pix[i], img.Pix[i+1], img.Pix[i+2]
+			key := uint32(r>>binShift)<<16 | uint32(g>>binShift)<<8 | uint32(bl>>binShift)
+
+			cb := h.bins[key]
+			if cb == nil {
+				cb = new(colorBin)
+				h.bins[key] = cb
+			}
+			cb.r += uint64(r)
+			cb.g += uint64(g)
+			cb.b += uint64(bl)
+			cb.n++
+
+			i += 4
+		}
+	}
+}
+
+// cutBox is a set of bins that becomes one palette entry.
+type cutBox struct {
+	bins []*colorBin
+	n    uint64
+}
+
+// widest returns the channel with the largest spread and the spread.
+func (bx *cutBox) widest() (channel int, spread int) {
+	lo := [3]uint8{255, 255, 255}
+	hi := [3]uint8{}
+	for _, cb := range bx.bins {
+		m := cb.mean()
+		for c := 0; c < 3; c++ {
+			if m[c] < lo[c] {
+				lo[c] = m[c]
+			}
+			if m[c] > hi[c] {
+				hi[c] = m[c]
+			}
+		}
+	}
+
+	for c := 0; c < 3; c++ {
+		if s := int(hi[c]) - int(lo[c]); s > spread {
+			channel, spread = c, s
+		}
+	}
+
+	return channel, spread
+}
+
+// split divides the box at the pixel weighted median of channel.
+func (bx *cutBox) split(channel int) (a, b cutBox) {
+	sort.Slice(bx.bins, func(i, j int) bool {
+		return bx.bins[i].mean()[channel] < bx.bins[j].mean()[channel]
+	})
+
+	half := bx.n / 2
+	var sum uint64
+	at := 1
+	for i, cb := range bx.bins[:len(bx.bins)-1] {
+		sum += cb.n
+		at = i + 1
+		if sum >= half {
+			break
+		}
+	}
+
+	a = cutBox{bins: bx.bins[:at]}
+	b = cutBox{bins: bx.bins[at:]}
+	for _, cb := range a.bins {
+		a.n += cb.n
+	}
+	b.n = bx.n - a.n
+
+	return a, b
+}
+
+// color returns the pixel weighted average of the box.
+func (bx *cutBox) color() color.RGBA {
+	var r, g, b uint64
+	for _, cb := range bx.bins {
+		r += cb.r
+		g += cb.g
+		b += cb.b
+	}
+	return color.RGBA{R: uint8(r / bx.n), G: uint8(g / bx.n), B: uint8(b / bx.n), A: 255}
+}
+
+// MedianCut builds an opaque palette of at most n colors. The box
+// with the widest channel is repeatedly split at its median until
+// there are n boxes or no box can be split.
+func (h *Histogram) MedianCut(n int) color.Palette {
+	if n < 1 {
+		n = 1
+	}
+
+	all := cutBox{}
+	for _, cb := range h.bins {
+		all.bins = append(all.bins, cb)
+		all.n += cb.n
+	}
+	if all.n == 0 {
+		return color.Palette{color.RGBA{A: 255}}
+	}
+
+	boxes := []cutBox{all}
+	for len(boxes) < n {
+		best, bestChannel, bestSpread := -1, 0, 0
+		for i := range boxes {
+			if len(boxes[i].bins) < 2 {
+				continue
+			}
+			channel, spread := boxes[i].widest()
+			if spread > bestSpread {
+				best, bestChannel, bestSpread = i, channel, spread
+			}
+		}
+		if best < 0 {
+			break
+		}
+
+		a, b := boxes[best].split(bestChannel)
+		boxes[best] = a
+		boxes = append(boxes, b)
+	}
+
+	palette := make(color.Palette, len(boxes))
+	for i := range boxes {
+		palette[i] = boxes[i].color()
+	}
+
+	return palette
+}
@@ -0,0 +1,127 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// stripes returns an image with a column per color, 'weights' pixels
+// tall each.
+func stripes(colors []color.RGBA, weights []int) *image.RGBA {
+	h := 0
+	for _, w := range weights {
+		if w > h {
+			h = w
+		}
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, len(colors), h))
+	for x, c := range colors {
+		for y := 0; y < weights[x]; y++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestMedianCut(t *testing.T) {
+	red := color.RGBA{R: 255, A: 255}
+	green := color.RGBA{G: 255, A: 255}
+	blue := color.RGBA{B: 255, A: 255}
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+
+	tests := []struct {
+		name    string
+		colors  []color.RGBA
+		weights []int
+		n       int
+		want    []color.RGBA
+	}{
+		{"exact", []color.RGBA{red, green, blue, white}, []int{1, 1, 1, 1}, 4, []color.RGBA{red, green, blue, white}},
+		{"more than needed", []color.RGBA{red, blue}, []int{1, 1}, 16, []color.RGBA{red, blue}},
+		{"single", []color.RGBA{red}, []int{3}, 1, []color.RGBA{red}},
+		// Both colors fall in one bin, which keeps their exact mean
+		{"binned mean", []color.RGBA{{R: 8, G: 8, B: 8, A: 255}, {R: 10, G: 10, B: 10, A: 255}}, []int{1, 1}, 4, []color.RGBA{{R: 9, G: 9, B: 9, A: 255}}},
+		// The mean is weighted by pixel count
+		{"weighted", []color.RGBA{{A: 255}, {R: 200, A: 255}}, []int{3, 1}, 1, []color.RGBA{{R: 50, A: 255}}},
+	}
+
+	for _, tt := range tests {
+		img := stripes(tt.colors, tt.weights)
+		h := NewHistogram()
+		// Count the pixels that were set, not the transparent fill
+		for x := range tt.colors {
+			h.Add(img.SubImage(image.Rect(x, 0, x+1, tt.weights[x])).(*image.RGBA))
+		}
+
+		palette := h.MedianCut(tt.n)
+		if len(palette) != len(tt.want) {
+			t.Errorf("%s: %d colors, want %d: %v", tt.name, len(palette), len(tt.want), palette)
+			continue
+		}
+		for _, c := range tt.want {
+			found := false
+			for _, p := range palette {
+				if p == color.Color(c) {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("%s: %v missing from %v", tt.name, c, palette)
+			}
+		}
+	}
+}
+
+func TestMedianCutSplitsWidestChannel(t *testing.T) {
+	// Red spans far more than green, so two colors split on red
+	var colors []color.RGBA
+	var weights []int
+	for i := 0; i < 8; i++ {
+		colors = append(colors, color.RGBA{R: uint8(i * 32), G: uint8(100 + i*4), A: 255})
+		weights = append(weights, 1)
+	}
+
+	h := NewHistogram()
+	h.Add(stripes(colors, weights))
+	palette := h.MedianCut(2)
+	if len(palette) != 2 {
+		t.Fatalf("%d colors, want 2", len(palette))
+	}
+
+	a := palette[0].(color.RGBA)
+	b := palette[1].(color.RGBA)
+	if a.R > b.R {
+		a, b = b, a
+	}
+	if a.R != 48 || b.R != 176 {
+		t.Errorf("split into red %d and %d, want 48 and 176", a.R, b.R)
+	}
+}
+
+func TestMedianCutEmpty(t *testing.T) {
+	palette := NewHistogram().MedianCut(16)
+	if len(palette) != 1 || palette[0] != color.Color(color.RGBA{A: 255}) {
+		t.Errorf("empty histogram gave %v, want opaque black", palette)
+	}
+}
+
+func TestGIFRecorderMaxFrames(t *testing.T) {
+	r := NewGIFRecorder()
+	r.FrameRate = 0
+	r.MaxFrames = 3
+
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	for i := 0; i < 5; i++ {
+		r.AddFrame(img, 0.1)
+	}
+
+	if r.Len() != 3 || !r.Full() {
+		t.Errorf("kept %d frames, full %v; want 3, true", r.Len(), r.Full())
+	}
+	// Dropped frames don't lengthen the last one
+	if d := r.delays(); len(d) != 3 || d[2] != 10 {
+		t.Errorf("delays %v, want [10 10 10]", d)
+	}
+}
@@ -0,0 +1,44 @@
+package imaging
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"io"
+)
+
+// EncodePPM writes img as a binary (P6) portable pixmap. Alpha is
+// dropped.
+func EncodePPM(w io.Writer, img image.Image) error {
+	src := toNRGBA(img)
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "P6\n%d %d\n255\n", src.Rect.Dx(), src.Rect.Dy())
+
+	row := make([]byte, src.Rect.Dx()*3)
+	for y := 0; y < src.Rect.Dy(); y++ {
+		pix := src.Pix[y*src.Stride:]
+		for x := 0; x < src.Rect.Dx(); x++ {
+			copy(row[x*3:x*3+3], pix[x*4:x*4+3])
+		}
+		bw.Write(row)
+	}
+
+	return bw.Flush()
+}
+
+// EncodePAM writes img as a portable arbitrary map (P7) with the
+// RGB_ALPHA tuple type.
+func EncodePAM(w io.Writer, img image.Image) error {
+	src := toNRGBA(img)
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "P7\nWIDTH %d\nHEIGHT %d\nDEPTH 4\nMAXVAL 255\nTUPLTYPE RGB_ALPHA\nENDHDR\n",
+		src.Rect.Dx(), src.Rect.Dy())
+
+	for y := 0; y < src.Rect.Dy(); y++ {
+		bw.Write(src.Pix[y*src.Stride : y*src.Stride+src.Rect.Dx()*4])
+	}
+
+	return bw.Flush()
+}
@@ -0,0 +1,47 @@
+package imaging
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"image"
+	"io"
+)
+
+const (
+	tgaTrueColor = 2
+	// Image descriptor: 8 alpha bits, rows stored top to bottom
+	tgaDescriptor = 8 | 0x20
+)
+
+// EncodeTGA writes img as an uncompressed 32 bit Truevision TGA.
+func EncodeTGA(w io.Writer, img image.Image) error {
+	src := toNRGBA(img)
+	width, height := src.Rect.Dx(), src.Rect.Dy()
+	if width > 0xffff || height > 0xffff {
+		return errors.New("tga: image is larger than 65535 pixels")
+	}
+
+	bw := bufio.NewWriter(w)
+
+	header := make([]byte, 18)
+	header[2] = tgaTrueColor
+	binary.LittleEndian.PutUint16(header[12:], uint16(width))
+	binary.LittleEndian.PutUint16(header[14:], uint16(height))
+	header[16] = 32
+	header[17] = tgaDescriptor
+	bw.Write(header)
+
+	// Pixels are stored BGRA
+	row := make([]byte, width*4)
+	for y := 0; y < height; y++ {
+		pix := src.Pix[y*src.Stride:]
+		for x := 0; x < width; x++ {
+			i := x * 4
+			row[i], row[i+1], row[i+2], row[i+3] = pix[i+2], pix[i+1], pix[i], pix[i+3]
+		}
+		bw.Write(row)
+	}
+
+	return bw.Flush()
+}
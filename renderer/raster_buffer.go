@@ -6,9 +6,11 @@ import (
 	"image/color"
 )
 
-// RasterBuffer provides a memory mapped RGBA and Z buffer
+// RasterBuffer provides a memory mapped RGBA, Z and stencil buffer
 // This buffer must be blitted to another buffer, for example,
 // PNG or display buffer (like SDL).
+// All buffers are stored as flat row-major slices, i.e. a pixel's
+// index is y*width + x, so scanlines walk contiguous memory.
 type RasterBuffer struct {
 	width  int
 	height int
@@ -19,9 +21,42 @@ type RasterBuffer struct {
 
 	// ZBuffer
 	ClearDepth    float32
-	zBuf          [][]float32
+	zBuf          []float32
 	alphaBlending bool
 
+	// When false only the depth and stencil buffers are written
+	colorWrite bool
+
+	// Stencil buffer
+	ClearStencil     uint8
+	stencilBuf       []uint8
+	stencilTest      bool
+	stencilFunc      api.StencilFunc
+	stencilRef       uint8
+	stencilMask      uint8
+	stencilWriteMask uint8
+	stencilFail      api.StencilOp
+	depthFail        api.StencilOp
+	depthPass        api.StencilOp
+
+	// Polyline style
+	lineWidth  float32
+	lineCap    api.LineCap
+	lineJoin   api.LineJoin
+	dash       []float32
+	dashOffset float32
+	// MiterLimit is the maximum ratio of miter length to line width
+	// before a miter join is drawn as a bevel.
+	MiterLimit float32
+
+	// Coverage mask of the polyline being drawn along with the
+	// bounds of the marked region.
+	lineMask                       []bool
+	maskX0, maskY0, maskX1, maskY1 int
+
+	// Scratch row extents for filled conics
+	extents []int
+
 	// Pen colors
 	ClearColor color.RGBA
 	PixelColor color.RGBA
@@ -34,6 +69,7 @@ func NewRasterBuffer(width, height int) api.IRasterBuffer {
 	o.height = height
 
 	o.alphaBlending = false
+	o.colorWrite = true
 
 	o.bounds = image.Rect(0, 0, width, height)
 	o.pixels = image.NewRGBA(o.bounds)
@@ -44,10 +80,23 @@ func NewRasterBuffer(width, height int) api.IRasterBuffer {
 	o.ClearColor.A = 255
 
 	o.ClearDepth = -100000000.0 // Default value
-	o.zBuf = make([][]float32, width)
-	for i := range o.zBuf {
-		o.zBuf[i] = make([]float32, height)
-	}
+	o.zBuf = make([]float32, width*height)
+
+	// The stencil test defaults to a pass-through that never
+	// modifies the buffer.
+	o.stencilTest = false
+	o.stencilFunc = api.StencilAlways
+	o.stencilMask = 0xff
+	o.stencilWriteMask = 0xff
+	o.stencilFail = api.StencilKeep
+	o.depthFail = api.StencilKeep
+	o.depthPass = api.StencilKeep
+	o.stencilBuf = make([]uint8, width*height)
+
+	o.lineWidth = 1.0
+	o.lineCap = api.CapButt
+	o.lineJoin = api.JoinMiter
+	o.MiterLimit = 4.0
 
 	return o
 }
@@ -57,77 +106,179 @@ func (rb *RasterBuffer) EnableAlphaBlending(enable bool) {
 	rb.alphaBlending = enable
 }
 
+// EnableColorWrite turns on/off writes to the color buffer. With color
+// writes off a primitive only lays down depth, for example, to prime
+// the depth buffer for hidden line removal.
+func (rb *RasterBuffer) EnableColorWrite(enable bool) {
+	rb.colorWrite = enable
+}
+
+// ColorWriteEnabled reports if writes to the color buffer are on.
+func (rb *RasterBuffer) ColorWriteEnabled() bool {
+	return rb.colorWrite
+}
+
 // Pixels returns underlying color buffer
 func (rb *RasterBuffer) Pixels() *image.RGBA {
 	return rb.pixels
 }
 
-// Clear clears both color and depth buffers
-func (rb *RasterBuffer) Clear() {
-	for y := 0; y < rb.height; y++ {
-		for x := 0; x < rb.width; x++ {
-			rb.pixels.SetRGBA(x, y, rb.ClearColor)
-			rb.zBuf[x][y] = rb.ClearDepth
-		}
+// Depth returns the underlying depth buffer, row-major with a pixel's
+// depth at y*width + x. Larger values are closer.
+func (rb *RasterBuffer) Depth() []float32 {
+	return rb.zBuf
+}
+
+// DepthAt returns the depth at x,y, or ClearDepth outside the buffer.
+func (rb *RasterBuffer) DepthAt(x, y int) float32 {
+	if x < 0 || y < 0 || x >= rb.width || y >= rb.height {
+		return rb.ClearDepth
 	}
+	return rb.zBuf[y*rb.width+x]
+}
+
+// GetClearDepth returns the value the depth buffer is cleared to.
+func (rb *RasterBuffer) GetClearDepth() float32 {
+	return rb.ClearDepth
+}
+
+// EnableStencilTest turns on/off the per pixel stencil test
+func (rb *RasterBuffer) EnableStencilTest(enable bool) {
+	rb.stencilTest = enable
+}
+
+// SetStencilFunc sets the comparison function, reference value and
+// compare mask used by the stencil test.
+func (rb *RasterBuffer) SetStencilFunc(fn api.StencilFunc, ref, mask uint8) {
+	rb.stencilFunc = fn
+	rb.stencilRef = ref
+	rb.stencilMask = mask
+}
+
+// SetStencilOp sets the actions taken when the stencil test fails (sfail),
+// the stencil test passes but the depth test fails (dpfail), and
+// both tests pass (dppass).
+func (rb *RasterBuffer) SetStencilOp(sfail, dpfail, dppass api.StencilOp) {
+	rb.stencilFail = sfail
+	rb.depthFail = dpfail
+	rb.depthPass = dppass
+}
+
+// SetStencilWriteMask controls which stencil bits can be written.
+func (rb *RasterBuffer) SetStencilWriteMask(mask uint8) {
+	rb.stencilWriteMask = mask
+}
+
+// Clear clears the color, depth and stencil buffers
+func (rb *RasterBuffer) Clear() {
+	rb.ClearColorBuffer()
+	rb.ClearDepthBuffer()
+	rb.ClearStencilBuffer()
 }
 
-// ClearColorBuffer clears only the color/pixel buffer
+// ClearColorBuffer clears only the color/pixel buffer.
+// The first pixel is seeded and then the filled region is doubled
+// with each copy until the buffer is covered.
 func (rb *RasterBuffer) ClearColorBuffer() {
-	/// TODO use image/draw to clear using a SRC
-	for y := 0; y < rb.height; y++ {
-		for x := 0; x < rb.width; x++ {
-			rb.pixels.SetRGBA(x, y, rb.ClearColor)
-		}
+	pix := rb.pixels.Pix
+	if len(pix) == 0 {
+		return
+	}
+
+	c := rb.ClearColor
+	pix[0] = c.R
+	pix[1] = c.G
+	pix[2] = c.B
+	pix[3] = c.A
+
+	for n := 4; n < len(pix); n *= 2 {
+		copy(pix[n:], pix[:n])
 	}
 }
 
 // ClearDepthBuffer sets the z buffer to ClearDepth
 func (rb *RasterBuffer) ClearDepthBuffer() {
-	for y := 0; y < rb.height; y++ {
-		for x := 0; x < rb.width; x++ {
-			rb.zBuf[x][y] = rb.ClearDepth
-		}
+	if len(rb.zBuf) == 0 {
+		return
+	}
+
+	rb.zBuf[0] = rb.ClearDepth
+	for n := 1; n < len(rb.zBuf); n *= 2 {
+		copy(rb.zBuf[n:], rb.zBuf[:n])
+	}
+}
+
+// ClearStencilBuffer sets the stencil buffer to ClearStencil
+func (rb *RasterBuffer) ClearStencilBuffer() {
+	if len(rb.stencilBuf) == 0 {
+		return
+	}
+
+	rb.stencilBuf[0] = rb.ClearStencil
+	for n := 1; n < len(rb.stencilBuf); n *= 2 {
+		copy(rb.stencilBuf[n:], rb.stencilBuf[:n])
 	}
 }
 
-// SetPixel sets a pixel and rejects based on a Z buffer.
+// SetPixel sets a pixel and rejects based on the stencil and Z buffers.
 // Returns:
 // -1 = pixel is beyond screen
 // 0 = pixel was farther away and ignored
 // 1 = pixel is closer and was entered into framebuffer and zbuffer
 // 2 = pixel is exact/(on top) and was ignored
+// 3 = pixel failed the stencil test and was ignored
 func (rb *RasterBuffer) SetPixel(x, y int, z float32) int {
-	if x < 0 || x > rb.width || y < 0 || y > rb.height {
+	if x < 0 || x >= rb.width || y < 0 || y >= rb.height {
 		return -1
 	}
 
-	zd := rb.zBuf[x][y]
+	i := y*rb.width + x
+
+	if rb.stencilTest && !rb.stencilPasses(i) {
+		rb.applyStencilOp(i, rb.stencilFail)
+		return 3
+	}
+
+	zd := rb.zBuf[i]
 
 	if z < zd {
 		//////////////////////////////////
 		// pixel farther away
 		//////////////////////////////////
+		if rb.stencilTest {
+			rb.applyStencilOp(i, rb.depthFail)
+		}
 		return 0
 	} else if z > zd {
 		//////////////////////////////////
 		// pixel closer (i.e. on top and visible)
 		//////////////////////////////////
-		rb.zBuf[x][y] = z
+		rb.zBuf[i] = z
+
+		if rb.stencilTest {
+			rb.applyStencilOp(i, rb.depthPass)
+		}
+
+		if !rb.colorWrite {
+			return 1
+		}
+
+		p := rb.pixels.Pix[i*4 : i*4+4 : i*4+4]
+		src := rb.PixelColor
 
 		// https://en.wikipedia.org/wiki/Alpha_compositing Alpha blending section
 		// Non premultiplied alpha
 		if rb.alphaBlending {
-			dst := rb.pixels.RGBAAt(x, y)
-			src := rb.PixelColor
 			A := float32(src.A) / 255.0
-			dst.R = uint8(float32(src.R)*A + float32(dst.R)*(1.0-A))
-			dst.G = uint8(float32(src.G)*A + float32(dst.G)*(1.0-A))
-			dst.B = uint8(float32(src.B)*A + float32(dst.B)*(1.0-A))
-			dst.A = 255
-			rb.pixels.SetRGBA(x, y, dst)
+			p[0] = uint8(float32(src.R)*A + float32(p[0])*(1.0-A))
+			p[1] = uint8(float32(src.G)*A + float32(p[1])*(1.0-A))
+			p[2] = uint8(float32(src.B)*A + float32(p[2])*(1.0-A))
+			p[3] = 255
 		} else {
-			rb.pixels.SetRGBA(x, y, rb.PixelColor)
+			p[0] = src.R
+			p[1] = src.G
+			p[2] = src.B
+			p[3] = src.A
 		}
 
 		return 1
@@ -135,16 +286,75 @@ func (rb *RasterBuffer) SetPixel(x, y int, z float32) int {
 		//////////////////////////////////
 		// pixel same distance
 		//////////////////////////////////
+		if rb.stencilTest {
+			rb.applyStencilOp(i, rb.depthFail)
+		}
 		return 2
 	}
 }
 
+func (rb *RasterBuffer) stencilPasses(i int) bool {
+	ref := rb.stencilRef & rb.stencilMask
+	s := rb.stencilBuf[i] & rb.stencilMask
+
+	switch rb.stencilFunc {
+	case api.StencilNever:
+		return false
+	case api.StencilLess:
+		return ref < s
+	case api.StencilLEqual:
+		return ref <= s
+	case api.StencilGreater:
+		return ref > s
+	case api.StencilGEqual:
+		return ref >= s
+	case api.StencilEqual:
+		return ref == s
+	case api.StencilNotEqual:
+		return ref != s
+	}
+
+	return true
+}
+
+func (rb *RasterBuffer) applyStencilOp(i int, op api.StencilOp) {
+	s := rb.stencilBuf[i]
+	v := s
+
+	switch op {
+	case api.StencilKeep:
+		return
+	case api.StencilZero:
+		v = 0
+	case api.StencilReplace:
+		v = rb.stencilRef
+	case api.StencilIncr:
+		if v < 255 {
+			v++
+		}
+	case api.StencilDecr:
+		if v > 0 {
+			v--
+		}
+	case api.StencilInvert:
+		v = ^v
+	}
+
+	// Only the bits enabled by the write mask are modified.
+	rb.stencilBuf[i] = (s &^ rb.stencilWriteMask) | (v & rb.stencilWriteMask)
+}
+
 // SetPixelColor set the current pixel color and sets the pixel
 // using SetPixel()
 func (rb *RasterBuffer) SetPixelColor(c color.RGBA) {
 	rb.PixelColor = c
 }
 
+// GetPixelColor returns the current pixel color
+func (rb *RasterBuffer) GetPixelColor() color.RGBA {
+	return rb.PixelColor
+}
+
 // DrawLine draws a line into the buffer
 func (rb *RasterBuffer) DrawLine(xP, yP, xQ, yQ int, zP, zQ float32) {
 	if xP < 0 || xP > rb.width-1 || xQ < 0 || xQ > rb.width-1 {
@@ -216,11 +426,12 @@ func (rb *RasterBuffer) DrawLine(xP, yP, xQ, yQ int, zP, zQ float32) {
 	}
 }
 
-// DrawLineAmmeraal has no zbuffer support
+// DrawLineAmmeraal draws a line interpolating depth linearly from zP to zQ
 func (rb *RasterBuffer) DrawLineAmmeraal(xP, yP, xQ, yQ int, zP, zQ float32) {
 	x := xP
 	y := yP
 	d := 0
+	z := zP
 
 	yInc := 1
 	xInc := 1
@@ -242,6 +453,11 @@ func (rb *RasterBuffer) DrawLineAmmeraal(xP, yP, xQ, yQ int, zP, zQ float32) {
 		m := dy << 1
 		c := dx << 1
 
+		dz := float32(0.0)
+		if dx > 0 {
+			dz = (zQ - zP) / float32(dx)
+		}
+
 		if xInc < 0 {
 			dx++
 		}
@@ -249,7 +465,7 @@ func (rb *RasterBuffer) DrawLineAmmeraal(xP, yP, xQ, yQ int, zP, zQ float32) {
 		col := uint8(0)
 		for true {
 			// rb.SetPixelColor(color.RGBA{R: 0, G: col, B: col, A: 255})
-			rb.SetPixel(x, y, zP)
+			rb.SetPixel(x, y, z)
 			col += 3
 
 			if x == xQ {
@@ -258,6 +474,7 @@ func (rb *RasterBuffer) DrawLineAmmeraal(xP, yP, xQ, yQ int, zP, zQ float32) {
 
 			// X is the major step axis
 			x += xInc
+			z += dz
 			d += m
 			if d >= dx {
 				y += yInc
@@ -268,6 +485,8 @@ func (rb *RasterBuffer) DrawLineAmmeraal(xP, yP, xQ, yQ int, zP, zQ float32) {
 		c := dy << 1
 		m := dx << 1
 
+		dz := (zQ - zP) / float32(dy)
+
 		if yInc < 0 {
 			dy++
 		}
@@ -275,7 +494,7 @@ func (rb *RasterBuffer) DrawLineAmmeraal(xP, yP, xQ, yQ int, zP, zQ float32) {
 		col := uint8(0)
 		for true {
 			// rb.SetPixelColor(color.RGBA{R: col, G: 0, B: 0, A: 255})
-			rb.SetPixel(x, y, zP)
+			rb.SetPixel(x, y, z)
 			col += 3
 
 			if y == yQ {
@@ -284,6 +503,7 @@ func (rb *RasterBuffer) DrawLineAmmeraal(xP, yP, xQ, yQ int, zP, zQ float32) {
 
 			// Y is the major step axis
 			y += yInc
+			z += dz
 			d += m
 			if d >= dy {
 				x += xInc
@@ -308,9 +528,7 @@ func (rb *RasterBuffer) FillTriangleAmmeraal(leftEdge, rightEdge api.IEdge, skip
 		}
 	}
 
-	for x := lx; x <= rx; x++ {
-		rb.SetPixel(x, ly, leftEdge.Z1())
-	}
+	rb.FillSpan(lx, rx, ly, leftEdge.Z(), rightEdge.Z())
 
 	for leftEdge.Step() {
 		lx, ly = leftEdge.XY()
@@ -334,21 +552,81 @@ func (rb *RasterBuffer) FillTriangleAmmeraal(leftEdge, rightEdge api.IEdge, skip
 			}
 		}
 
+		lz := leftEdge.Z()
+		rz := rightEdge.Z()
+
 		// We always want to fill the scanline from left to right
 		if lx > rx {
 			t := rx
 			rx = lx
 			lx = t
+			tz := rz
+			rz = lz
+			lz = tz
 		}
 
 		// The last pixel may be shared with another edge. That edge
 		// will render it. Thus the top-left rendering rule.
 		if skipRight {
+			if rx > lx {
+				rz -= (rz - lz) / float32(rx-lx)
+			}
 			rx--
 		}
 		// Fill scanline
-		for x := lx; x <= rx; x++ {
-			rb.SetPixel(x, ly, leftEdge.Z1())
+		rb.FillSpan(lx, rx, ly, lz, rz)
+	}
+}
+
+// FillSpan fills the scanline y from xl to xr inclusive interpolating
+// depth from zl to zr. Without blending, stenciling or color masking the
+// span is depth tested and written directly into Pix, otherwise each
+// pixel goes through SetPixel.
+func (rb *RasterBuffer) FillSpan(xl, xr, y int, zl, zr float32) {
+	if y < 0 || y >= rb.height || xl > xr {
+		return
+	}
+
+	dz := float32(0.0)
+	if xr > xl {
+		dz = (zr - zl) / float32(xr-xl)
+	}
+
+	if xl < 0 {
+		zl += dz * float32(-xl)
+		xl = 0
+	}
+	if xr > rb.width-1 {
+		xr = rb.width - 1
+	}
+	if xl > xr {
+		return
+	}
+
+	if rb.alphaBlending || rb.stencilTest || !rb.colorWrite {
+		z := zl
+		for x := xl; x <= xr; x++ {
+			rb.SetPixel(x, y, z)
+			z += dz
+		}
+		return
+	}
+
+	c := rb.PixelColor
+	i := y*rb.width + xl
+	zSpan := rb.zBuf[i : i+xr-xl+1]
+	pix := rb.pixels.Pix[i*4 : (i+len(zSpan))*4]
+
+	z := zl
+	for j, zd := range zSpan {
+		if z > zd {
+			zSpan[j] = z
+			p := pix[j*4 : j*4+4 : j*4+4]
+			p[0] = c.R
+			p[1] = c.G
+			p[2] = c.B
+			p[3] = c.A
 		}
+		z += dz
 	}
 }
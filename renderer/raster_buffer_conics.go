@@ -0,0 +1,231 @@
+package renderer
+
+import "math"
+
+// Circles, ellipses and arcs are rasterized with the midpoint algorithms.
+// Each produces the points of a single quadrant which are then mirrored
+// into the other three. Points are never entered twice so blending is
+// applied once per pixel.
+
+// DrawCircle draws a circle outline centered on xc,yc
+func (rb *RasterBuffer) DrawCircle(xc, yc, r int, z float32) {
+	rb.DrawEllipse(xc, yc, r, r, z)
+}
+
+// FillCircle draws a filled circle centered on xc,yc
+func (rb *RasterBuffer) FillCircle(xc, yc, r int, z float32) {
+	rb.FillEllipse(xc, yc, r, r, z)
+}
+
+// DrawEllipse draws an axis aligned ellipse outline centered on xc,yc
+func (rb *RasterBuffer) DrawEllipse(xc, yc, rx, ry int, z float32) {
+	conicQuadrant(rx, ry, func(x, y int) {
+		mirror4(x, y, func(dx, dy int) {
+			rb.SetPixel(xc+dx, yc+dy, z)
+		})
+	})
+}
+
+// FillEllipse draws a filled axis aligned ellipse centered on xc,yc
+func (rb *RasterBuffer) FillEllipse(xc, yc, rx, ry int, z float32) {
+	extents := rb.conicExtents(rx, ry)
+	if extents == nil {
+		return
+	}
+
+	for y, hw := range extents {
+		rb.FillSpan(xc-hw, xc+hw, yc+y, z, z)
+		if y != 0 {
+			rb.FillSpan(xc-hw, xc+hw, yc-y, z, z)
+		}
+	}
+}
+
+// DrawArc draws the part of an ellipse outline between the 'start' and
+// 'end' angles. Angles are in radians and measured counter-clockwise, as
+// seen on the display, from the +X axis.
+func (rb *RasterBuffer) DrawArc(xc, yc, rx, ry int, start, end float32, z float32) {
+	s, sweep := arcRange(start, end)
+
+	conicQuadrant(rx, ry, func(x, y int) {
+		mirror4(x, y, func(dx, dy int) {
+			if inArc(dx, dy, s, sweep) {
+				rb.SetPixel(xc+dx, yc+dy, z)
+			}
+		})
+	})
+}
+
+// FillArc draws a filled elliptical pie slice between the 'start' and
+// 'end' angles. See DrawArc for the angle convention.
+func (rb *RasterBuffer) FillArc(xc, yc, rx, ry int, start, end float32, z float32) {
+	extents := rb.conicExtents(rx, ry)
+	if extents == nil {
+		return
+	}
+
+	s, sweep := arcRange(start, end)
+
+	fillRow := func(dy, hw int) {
+		for dx := -hw; dx <= hw; dx++ {
+			if inArc(dx, dy, s, sweep) {
+				rb.SetPixel(xc+dx, yc+dy, z)
+			}
+		}
+	}
+
+	for y, hw := range extents {
+		fillRow(y, hw)
+		if y != 0 {
+			fillRow(-y, hw)
+		}
+	}
+}
+
+// conicExtents returns the half width of each row of the first quadrant
+// indexed by y. The slice is reused between calls. A flat ellipse,
+// ry == 0, has the single row of its line.
+func (rb *RasterBuffer) conicExtents(rx, ry int) []int {
+	if rx < 0 || ry < 0 {
+		return nil
+	}
+
+	if cap(rb.extents) < ry+1 {
+		rb.extents = make([]int, ry+1)
+	}
+	extents := rb.extents[:ry+1]
+	for i := range extents {
+		extents[i] = 0
+	}
+
+	conicQuadrant(rx, ry, func(x, y int) {
+		if x > extents[y] {
+			extents[y] = x
+		}
+	})
+
+	return extents
+}
+
+// conicQuadrant calls 'plot' for every point of the first quadrant of an
+// ellipse, each exactly once. Circles use the midpoint circle algorithm,
+// mirroring its octant across the diagonal.
+func conicQuadrant(rx, ry int, plot func(x, y int)) {
+	if rx < 0 || ry < 0 {
+		return
+	}
+
+	if rx == ry {
+		x := rx
+		y := 0
+		d := 1 - rx
+		for y <= x {
+			plot(x, y)
+			if x != y {
+				plot(y, x)
+			}
+			y++
+			if d < 0 {
+				d += 2*y + 1
+			} else {
+				x--
+				d += 2*(y-x) + 1
+			}
+		}
+		return
+	}
+
+	// A flat ellipse is a line. The midpoint steps below would stop
+	// at its first point.
+	if ry == 0 {
+		for x := 0; x <= rx; x++ {
+			plot(x, 0)
+		}
+		return
+	}
+
+	// Midpoint ellipse
+	a2 := float64(rx) * float64(rx)
+	b2 := float64(ry) * float64(ry)
+
+	x := 0
+	y := ry
+	px := 0.0
+	py := 2.0 * a2 * float64(y)
+
+	// Region 1 where the slope is > -1, x is the major step axis
+	p := b2 - a2*float64(ry) + 0.25*a2
+	plot(x, y)
+	for px < py {
+		x++
+		px += 2.0 * b2
+		if p < 0 {
+			p += b2 + px
+		} else {
+			y--
+			py -= 2.0 * a2
+			p += b2 + px - py
+		}
+		plot(x, y)
+	}
+
+	// Region 2 where y is the major step axis
+	fx := float64(x) + 0.5
+	fy := float64(y) - 1.0
+	p = b2*fx*fx + a2*fy*fy - a2*b2
+	for y > 0 {
+		y--
+		py -= 2.0 * a2
+		if p > 0 {
+			p += a2 - py
+		} else {
+			x++
+			px += 2.0 * b2
+			p += a2 - py + px
+		}
+		plot(x, y)
+	}
+}
+
+// mirror4 reflects a first quadrant point into all four quadrants without
+// repeating points that lie on an axis.
+func mirror4(x, y int, plot func(dx, dy int)) {
+	plot(x, y)
+	if x != 0 {
+		plot(-x, y)
+	}
+	if y != 0 {
+		plot(x, -y)
+		if x != 0 {
+			plot(-x, -y)
+		}
+	}
+}
+
+// arcRange normalizes an angle range to a start in [0, 2π) and a sweep.
+func arcRange(start, end float32) (s, sweep float64) {
+	s = math.Mod(float64(start), 2*math.Pi)
+	if s < 0 {
+		s += 2 * math.Pi
+	}
+
+	sweep = float64(end - start)
+	if sweep < 0 {
+		sweep = math.Mod(sweep, 2*math.Pi) + 2*math.Pi
+	}
+	return s, sweep
+}
+
+// inArc tests if the offset dx,dy from the center lies within the arc.
+// The display's +Y axis is downward so dy is flipped.
+func inArc(dx, dy int, start, sweep float64) bool {
+	if sweep >= 2*math.Pi || (dx == 0 && dy == 0) {
+		return true
+	}
+
+	a := math.Atan2(float64(-dy), float64(dx)) - start
+	if a < 0 {
+		a += 2 * math.Pi
+	}
+	return a <= sweep
+}
@@ -0,0 +1,59 @@
+package renderer
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// drawnPixels returns the pixels of rb not at the clear color.
+func drawnPixels(rb *RasterBuffer) []image.Point {
+	var pts []image.Point
+	pix := rb.Pixels()
+	b := pix.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if pix.RGBAAt(x, y) != rb.ClearColor {
+				pts = append(pts, image.Point{X: x, Y: y})
+			}
+		}
+	}
+	return pts
+}
+
+func TestDegenerateEllipse(t *testing.T) {
+	tests := []struct {
+		name   string
+		rx, ry int
+		want   image.Rectangle
+	}{
+		{"point", 0, 0, image.Rect(10, 10, 11, 11)},
+		{"horizontal", 5, 0, image.Rect(5, 10, 16, 11)},
+		{"vertical", 0, 5, image.Rect(10, 5, 11, 16)},
+	}
+
+	for _, tt := range tests {
+		for _, fill := range []bool{false, true} {
+			rb := NewRasterBuffer(21, 21).(*RasterBuffer)
+			rb.Clear()
+			rb.SetPixelColor(color.RGBA{R: 255, A: 255})
+			if fill {
+				rb.FillEllipse(10, 10, tt.rx, tt.ry, 1.0)
+			} else {
+				rb.DrawEllipse(10, 10, tt.rx, tt.ry, 1.0)
+			}
+
+			pts := drawnPixels(rb)
+			if len(pts) != tt.want.Dx()*tt.want.Dy() {
+				t.Errorf("%s fill=%v: drew %d pixels, want %d", tt.name, fill, len(pts), tt.want.Dx()*tt.want.Dy())
+				continue
+			}
+			for _, p := range pts {
+				if !p.In(tt.want) {
+					t.Errorf("%s fill=%v: pixel %v outside %v", tt.name, fill, p, tt.want)
+					break
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,402 @@
+package renderer
+
+import (
+	"SoftRenderer/api"
+	"math"
+)
+
+// Wide lines are rasterized in two passes. First every piece of the
+// polyline (segment quads, caps and joins) is marked in a coverage mask,
+// then each marked pixel is entered once through SetPixel. Overlapping
+// pieces therefore never blend twice.
+//
+// Polyline coordinates are float pixel positions where integer values
+// are pixel centers, matching the integer line APIs.
+
+// SetLineWidth sets the width, in pixels, of polylines
+func (rb *RasterBuffer) SetLineWidth(width float32) {
+	rb.lineWidth = width
+}
+
+// SetLineCap sets the shape of polyline and dash ends
+func (rb *RasterBuffer) SetLineCap(lineCap api.LineCap) {
+	rb.lineCap = lineCap
+}
+
+// SetLineJoin sets the shape of polyline corners
+func (rb *RasterBuffer) SetLineJoin(join api.LineJoin) {
+	rb.lineJoin = join
+}
+
+// SetLineDash sets a dash pattern of alternating on/off lengths in pixels
+// starting with "on". 'offset' shifts the start of the pattern along the
+// line and may be negative or larger than the pattern. A nil or empty
+// pattern draws solid lines.
+// For example, {1, 1} is a dotted stipple and {8, 4} a dashed one.
+func (rb *RasterBuffer) SetLineDash(pattern []float32, offset float32) {
+	rb.dash = nil
+	total := float32(0.0)
+	for _, d := range pattern {
+		if d < 0 {
+			d = 0
+		}
+		total += d
+		rb.dash = append(rb.dash, d)
+	}
+
+	// A pattern without any length can't be walked.
+	if total <= 0 {
+		rb.dash = nil
+	}
+
+	// An odd length pattern is repeated so on/off alternate consistently.
+	if len(rb.dash)%2 == 1 {
+		rb.dash = append(rb.dash, rb.dash...)
+		total *= 2
+	}
+
+	// Wrap the offset into one period so ever growing offsets, as
+	// in marching ants, cost the same to walk and negative ones
+	// shift the pattern the other way.
+	if rb.dash != nil {
+		offset = float32(math.Mod(float64(offset), float64(total)))
+		if offset < 0 {
+			offset += total
+		}
+	}
+	rb.dashOffset = offset
+}
+
+// DrawPolyline draws connected segments through 'points', given as x,y
+// pairs, at depth z using the current width, caps, joins and dash pattern.
+// If 'closed' is true the last point connects back to the first.
+func (rb *RasterBuffer) DrawPolyline(points []float32, z float32, closed bool) {
+	n := len(points) / 2
+	if n < 2 {
+		return
+	}
+
+	if rb.lineMask == nil {
+		rb.lineMask = make([]bool, rb.width*rb.height)
+	}
+	rb.maskX0 = rb.width
+	rb.maskY0 = rb.height
+	rb.maskX1 = -1
+	rb.maskY1 = -1
+
+	h := float64(rb.lineWidth) / 2.0
+	if h < 0.5 {
+		h = 0.5
+	}
+
+	segs := n - 1
+	if closed {
+		segs = n
+	}
+
+	// Dash state
+	dashIdx := 0
+	dashOn := true
+	dashLeft := float64(0.0)
+	if rb.dash != nil {
+		dashLeft = float64(rb.dash[0])
+		rb.advanceDash(float64(rb.dashOffset), &dashIdx, &dashOn, &dashLeft)
+	}
+
+	// A closed solid line has no ends so it joins at every vertex.
+	solidClosed := closed && rb.dash == nil
+
+	// Is the pen down as the previous segment ended.
+	penDown := false
+
+	for i := 0; i < segs; i++ {
+		ax := float64(points[i*2])
+		ay := float64(points[i*2+1])
+		j := (i + 1) % n
+		bx := float64(points[j*2])
+		by := float64(points[j*2+1])
+
+		dx := bx - ax
+		dy := by - ay
+		l := math.Hypot(dx, dy)
+		if l == 0 {
+			continue
+		}
+		dx /= l
+		dy /= l
+
+		if rb.dash == nil {
+			startCap := i == 0 && !closed
+			endCap := i == segs-1 && !closed
+			rb.markSegment(ax, ay, dx, dy, 0, l, h, startCap, endCap)
+			penDown = true
+		} else {
+			penWasDown := penDown
+			penDown = false
+
+			pos := 0.0
+			for pos < l {
+				step := math.Min(dashLeft, l-pos)
+				ends := dashLeft-step <= 0
+				if dashOn && step > 0 {
+					// A dash continuing through the vertex is joined, not capped.
+					startCap := pos > 0 || !penWasDown
+					endCap := ends || i == segs-1
+					rb.markSegment(ax, ay, dx, dy, pos, pos+step, h, startCap, endCap)
+					penDown = !ends
+				}
+				pos += step
+				dashLeft -= step
+				if dashLeft <= 0 {
+					rb.advanceDash(0, &dashIdx, &dashOn, &dashLeft)
+				}
+			}
+		}
+
+		// Join with the next segment
+		if penDown && (i < segs-1 || solidClosed) {
+			k := (j + 1) % n
+			rb.markJoin(bx, by, dx, dy, float64(points[k*2])-bx, float64(points[k*2+1])-by, h)
+		}
+	}
+
+	rb.flushLineMask(z)
+}
+
+// advanceDash moves to the next pattern entry when the current one is
+// used up, and then walks 'distance' further along the pattern.
+func (rb *RasterBuffer) advanceDash(distance float64, idx *int, on *bool, left *float64) {
+	for *left <= 0 || distance > 0 {
+		if *left <= 0 {
+			*idx = (*idx + 1) % len(rb.dash)
+			*on = *idx%2 == 0
+			*left = float64(rb.dash[*idx])
+			continue
+		}
+		step := math.Min(*left, distance)
+		*left -= step
+		distance -= step
+	}
+}
+
+// markSegment marks the part of a segment from 't0' to 't1' along the unit
+// direction (dx,dy) starting at (ax,ay), including any caps.
+func (rb *RasterBuffer) markSegment(ax, ay, dx, dy, t0, t1, h float64, startCap, endCap bool) {
+	if startCap {
+		switch rb.lineCap {
+		case api.CapSquare:
+			t0 -= h
+		case api.CapRound:
+			rb.markDisc(ax+dx*t0, ay+dy*t0, h)
+		}
+	}
+	if endCap {
+		switch rb.lineCap {
+		case api.CapSquare:
+			t1 += h
+		case api.CapRound:
+			rb.markDisc(ax+dx*t1, ay+dy*t1, h)
+		}
+	}
+
+	// The normal is the direction rotated by 90 degrees.
+	nx := -dy * h
+	ny := dx * h
+	x0 := ax + dx*t0
+	y0 := ay + dy*t0
+	x1 := ax + dx*t1
+	y1 := ay + dy*t1
+
+	rb.markConvex([]float64{
+		x0 + nx, y0 + ny,
+		x1 + nx, y1 + ny,
+		x1 - nx, y1 - ny,
+		x0 - nx, y0 - ny,
+	})
+}
+
+// markJoin marks the corner at (px,py) between an incoming unit direction
+// (d0x,d0y) and an outgoing direction (d1x,d1y).
+func (rb *RasterBuffer) markJoin(px, py, d0x, d0y, d1x, d1y, h float64) {
+	l := math.Hypot(d1x, d1y)
+	if l == 0 {
+		return
+	}
+	d1x /= l
+	d1y /= l
+
+	if rb.lineJoin == api.JoinRound {
+		rb.markDisc(px, py, h)
+		return
+	}
+
+	// The outer side of the corner is opposite the turn direction.
+	cross := d0x*d1y - d0y*d1x
+	if cross == 0 {
+		// Straight through, nothing to fill. A reversal is capped round.
+		if d0x*d1x+d0y*d1y < 0 {
+			rb.markDisc(px, py, h)
+		}
+		return
+	}
+	s := 1.0
+	if cross > 0 {
+		s = -1.0
+	}
+
+	n0x := -d0y * s
+	n0y := d0x * s
+	n1x := -d1y * s
+	n1y := d1x * s
+
+	if rb.lineJoin == api.JoinMiter {
+		mx := n0x + n1x
+		my := n0y + n1y
+		ml := math.Hypot(mx, my)
+		if ml > 0 {
+			mx /= ml
+			my /= ml
+			// ratio of miter length to line width
+			cosHalf := mx*n0x + my*n0y
+			if cosHalf > 0 && 1.0/cosHalf <= float64(rb.MiterLimit) {
+				ext := h / cosHalf
+				rb.markConvex([]float64{
+					px, py,
+					px + n0x*h, py + n0y*h,
+					px + mx*ext, py + my*ext,
+					px + n1x*h, py + n1y*h,
+				})
+				return
+			}
+		}
+	}
+
+	// Bevel
+	rb.markConvex([]float64{
+		px, py,
+		px + n0x*h, py + n0y*h,
+		px + n1x*h, py + n1y*h,
+	})
+}
+
+// markConvex marks the pixel centers inside a convex polygon given as
+// x,y pairs.
+func (rb *RasterBuffer) markConvex(poly []float64) {
+	n := len(poly) / 2
+	minY := math.Inf(1)
+	maxY := math.Inf(-1)
+	for i := 0; i < n; i++ {
+		minY = math.Min(minY, poly[i*2+1])
+		maxY = math.Max(maxY, poly[i*2+1])
+	}
+
+	y0, y1 := rb.maskRows(minY, maxY)
+	for y := y0; y <= y1; y++ {
+		yc := float64(y)
+		xl := math.Inf(1)
+		xr := math.Inf(-1)
+		for i := 0; i < n; i++ {
+			ax := poly[i*2]
+			ay := poly[i*2+1]
+			j := (i + 1) % n
+			bx := poly[j*2]
+			by := poly[j*2+1]
+			if ay == by {
+				continue
+			}
+			if (yc >= ay && yc < by) || (yc >= by && yc < ay) {
+				x := ax + (yc-ay)*(bx-ax)/(by-ay)
+				xl = math.Min(xl, x)
+				xr = math.Max(xr, x)
+			}
+		}
+		if xl <= xr {
+			rb.markCenters(xl, xr, y)
+		}
+	}
+}
+
+// markDisc marks the pixel centers inside a disc.
+func (rb *RasterBuffer) markDisc(cx, cy, r float64) {
+	y0, y1 := rb.maskRows(cy-r, cy+r)
+	for y := y0; y <= y1; y++ {
+		dy := float64(y) - cy
+		w := r*r - dy*dy
+		if w < 0 {
+			continue
+		}
+		w = math.Sqrt(w)
+		rb.markCenters(cx-w, cx+w, y)
+	}
+}
+
+// maskRows returns the rows whose pixel centers lie from minY up to,
+// not including, maxY, limited to the buffer. Clamping before the
+// conversion to int keeps huge or infinite coordinates from walking
+// off-screen rows. y1 < y0 if there are none.
+func (rb *RasterBuffer) maskRows(minY, maxY float64) (y0, y1 int) {
+	minY = math.Ceil(math.Max(minY, 0))
+	maxY = math.Ceil(math.Min(maxY, float64(rb.height))) - 1
+	// NaN fails both comparisons
+	if !(minY <= maxY) {
+		return 0, -1
+	}
+	return int(minY), int(maxY)
+}
+
+// markCenters marks the pixel centers of row y from xl up to, not
+// including, xr. Like maskRows it clamps before converting to int.
+func (rb *RasterBuffer) markCenters(xl, xr float64, y int) {
+	xl = math.Max(xl, -1)
+	xr = math.Min(xr, float64(rb.width)+1)
+	if !(xl <= xr) {
+		return
+	}
+	rb.markSpan(int(math.Ceil(xl)), int(math.Ceil(xr))-1, y)
+}
+
+func (rb *RasterBuffer) markSpan(xl, xr, y int) {
+	if y < 0 || y >= rb.height {
+		return
+	}
+	if xl < 0 {
+		xl = 0
+	}
+	if xr > rb.width-1 {
+		xr = rb.width - 1
+	}
+	if xl > xr {
+		return
+	}
+
+	row := rb.lineMask[y*rb.width : (y+1)*rb.width]
+	for x := xl; x <= xr; x++ {
+		row[x] = true
+	}
+
+	if xl < rb.maskX0 {
+		rb.maskX0 = xl
+	}
+	if xr > rb.maskX1 {
+		rb.maskX1 = xr
+	}
+	if y < rb.maskY0 {
+		rb.maskY0 = y
+	}
+	if y > rb.maskY1 {
+		rb.maskY1 = y
+	}
+}
+
+// flushLineMask enters every marked pixel and clears the mask.
+func (rb *RasterBuffer) flushLineMask(z float32) {
+	for y := rb.maskY0; y <= rb.maskY1; y++ {
+		row := rb.lineMask[y*rb.width : (y+1)*rb.width]
+		for x := rb.maskX0; x <= rb.maskX1; x++ {
+			if row[x] {
+				row[x] = false
+				rb.SetPixel(x, y, z)
+			}
+		}
+	}
+}
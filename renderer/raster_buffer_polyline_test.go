@@ -0,0 +1,137 @@
+package renderer
+
+import (
+	"SoftRenderer/api"
+	"image/color"
+	"math"
+	"testing"
+	"time"
+)
+
+// newLineBuffer returns a cleared 40x40 buffer drawing in red.
+func newLineBuffer() *RasterBuffer {
+	rb := NewRasterBuffer(40, 40).(*RasterBuffer)
+	rb.Clear()
+	rb.SetPixelColor(color.RGBA{R: 255, A: 255})
+	return rb
+}
+
+func isDrawn(rb *RasterBuffer, x, y int) bool {
+	return rb.Pixels().RGBAAt(x, y) != rb.ClearColor
+}
+
+func TestPolylineCaps(t *testing.T) {
+	tests := []struct {
+		lineCap api.LineCap
+		count   int
+		// Beyond the end point on the center line, and the corner of
+		// a square cap
+		pastEnd, corner bool
+	}{
+		// Pixel centers x 10..19 on rows 8..11
+		{api.CapButt, 40, false, false},
+		// Discs of radius 2 at both ends. Centers are half open, so
+		// the right one adds 6 pixels and the left 4.
+		{api.CapRound, 50, true, false},
+		// Extended by 2 on both ends
+		{api.CapSquare, 56, true, true},
+	}
+
+	for _, tt := range tests {
+		rb := newLineBuffer()
+		rb.SetLineWidth(4)
+		rb.SetLineCap(tt.lineCap)
+		rb.DrawPolyline([]float32{10, 10, 20, 10}, 1.0, false)
+
+		if n := len(drawnPixels(rb)); n != tt.count {
+			t.Errorf("cap %v: %d pixels, want %d", tt.lineCap, n, tt.count)
+		}
+		if isDrawn(rb, 21, 10) != tt.pastEnd {
+			t.Errorf("cap %v: pixel past the end drawn %v, want %v", tt.lineCap, !tt.pastEnd, tt.pastEnd)
+		}
+		if isDrawn(rb, 21, 8) != tt.corner {
+			t.Errorf("cap %v: cap corner drawn %v, want %v", tt.lineCap, !tt.corner, tt.corner)
+		}
+	}
+}
+
+func TestPolylineJoins(t *testing.T) {
+	// A right angle turning up the screen. The outer corner is down
+	// and to the right of 30,30 and the miter's tip is at 33,33.
+	corner := []float32{10, 30, 30, 30, 30, 10}
+
+	tests := []struct {
+		name       string
+		join       api.LineJoin
+		miterLimit float32
+		tip        bool
+	}{
+		{"miter", api.JoinMiter, 4, true},
+		// A right angle miter is √2 times the width
+		{"miter over limit", api.JoinMiter, 1.2, false},
+		{"bevel", api.JoinBevel, 4, false},
+	}
+
+	counts := map[string]int{}
+	for _, tt := range tests {
+		rb := newLineBuffer()
+		rb.SetLineWidth(6)
+		rb.SetLineJoin(tt.join)
+		rb.MiterLimit = tt.miterLimit
+		rb.DrawPolyline(corner, 1.0, false)
+
+		counts[tt.name] = len(drawnPixels(rb))
+		if isDrawn(rb, 32, 32) != tt.tip {
+			t.Errorf("%s: miter tip drawn %v, want %v", tt.name, !tt.tip, tt.tip)
+		}
+		// The bevel's edge is always covered
+		if !isDrawn(rb, 31, 31) {
+			t.Errorf("%s: corner not joined", tt.name)
+		}
+	}
+
+	if counts["miter over limit"] != counts["bevel"] {
+		t.Errorf("miter over its limit drew %d pixels, bevel %d", counts["miter over limit"], counts["bevel"])
+	}
+	if counts["miter"] <= counts["bevel"] {
+		t.Errorf("miter drew %d pixels, not more than bevel's %d", counts["miter"], counts["bevel"])
+	}
+}
+
+func TestPolylineDash(t *testing.T) {
+	// {8, 4} repeats every 12 pixels. -2 starts 2 pixels before the
+	// end of a period, which is the same as starting at 10.
+	for _, offset := range []float32{-2, 10, 10 + 12*1000, -2 - 12*1000} {
+		rb := newLineBuffer()
+		rb.SetLineDash([]float32{8, 4}, offset)
+		rb.DrawPolyline([]float32{0, 5, 40, 5}, 1.0, false)
+
+		for x := 0; x < 40; x++ {
+			want := (x+10)%12 < 8
+			if isDrawn(rb, x, 5) != want {
+				t.Errorf("offset %v: pixel %d drawn %v, want %v", offset, x, !want, want)
+			}
+		}
+	}
+}
+
+func TestPolylineHugeCoordinates(t *testing.T) {
+	lines := [][]float32{
+		{-1e7, -1e7, 1e7, 1e7},
+		{20, 20, 20, 1e9},
+		{0, float32(math.Inf(1)), 10, 10},
+		{0, float32(math.NaN()), 10, 10},
+	}
+
+	start := time.Now()
+	for _, pts := range lines {
+		rb := newLineBuffer()
+		rb.SetLineWidth(1e6)
+		rb.SetLineCap(api.CapRound)
+		rb.DrawPolyline(pts, 1.0, false)
+	}
+	// Off-screen rows used to be walked one at a time
+	if d := time.Since(start); d > 2*time.Second {
+		t.Errorf("huge lines took %v", d)
+	}
+}
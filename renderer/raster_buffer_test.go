@@ -0,0 +1,177 @@
+package renderer
+
+import (
+	"image"
+	"testing"
+	"time"
+)
+
+var benchSizes = []struct {
+	name          string
+	width, height int
+}{
+	{"640x480", 640, 480},
+	{"1920x1080", 1920, 1080},
+}
+
+// reportPixelRate reports the throughput of a benchmark that touches
+// 'pixels' pixels per iteration over 'elapsed'.
+func reportPixelRate(b *testing.B, pixels int, elapsed time.Duration) {
+	b.ReportMetric(float64(pixels)*float64(b.N)/elapsed.Seconds(), "pixels/s")
+}
+
+func BenchmarkClear(b *testing.B) {
+	for _, sz := range benchSizes {
+		b.Run(sz.name, func(b *testing.B) {
+			rb := NewRasterBuffer(sz.width, sz.height).(*RasterBuffer)
+			b.ResetTimer()
+			start := time.Now()
+			for n := 0; n < b.N; n++ {
+				rb.Clear()
+			}
+			reportPixelRate(b, sz.width*sz.height, time.Since(start))
+		})
+	}
+}
+
+// BenchmarkClearPerPixel mirrors the previous Clear, which wrote each pixel
+// through SetRGBA and each depth through a column major [x][y] slice. It is
+// kept as a reference point for BenchmarkClear.
+func BenchmarkClearPerPixel(b *testing.B) {
+	for _, sz := range benchSizes {
+		b.Run(sz.name, func(b *testing.B) {
+			rb := NewRasterBuffer(sz.width, sz.height).(*RasterBuffer)
+			pixels := image.NewRGBA(image.Rect(0, 0, sz.width, sz.height))
+			zBuf := make([][]float32, sz.width)
+			for i := range zBuf {
+				zBuf[i] = make([]float32, sz.height)
+			}
+			b.ResetTimer()
+			start := time.Now()
+			for n := 0; n < b.N; n++ {
+				for y := 0; y < sz.height; y++ {
+					for x := 0; x < sz.width; x++ {
+						pixels.SetRGBA(x, y, rb.ClearColor)
+						zBuf[x][y] = rb.ClearDepth
+					}
+				}
+			}
+			reportPixelRate(b, sz.width*sz.height, time.Since(start))
+		})
+	}
+}
+
+func BenchmarkFillSpan(b *testing.B) {
+	for _, sz := range benchSizes {
+		b.Run(sz.name, func(b *testing.B) {
+			rb := NewRasterBuffer(sz.width, sz.height).(*RasterBuffer)
+			b.ResetTimer()
+			start := time.Now()
+			for n := 0; n < b.N; n++ {
+				// An ever closer z makes every pixel pass the depth test.
+				z := float32(n)
+				for y := 0; y < sz.height; y++ {
+					rb.FillSpan(0, sz.width-1, y, z, z)
+				}
+			}
+			reportPixelRate(b, sz.width*sz.height, time.Since(start))
+		})
+	}
+}
+
+// BenchmarkFillSpanPerPixel fills the same area one SetPixel at a time
+// as a reference point for BenchmarkFillSpan.
+func BenchmarkFillSpanPerPixel(b *testing.B) {
+	for _, sz := range benchSizes {
+		b.Run(sz.name, func(b *testing.B) {
+			rb := NewRasterBuffer(sz.width, sz.height).(*RasterBuffer)
+			b.ResetTimer()
+			start := time.Now()
+			for n := 0; n < b.N; n++ {
+				z := float32(n)
+				for y := 0; y < sz.height; y++ {
+					for x := 0; x < sz.width; x++ {
+						rb.SetPixel(x, y, z)
+					}
+				}
+			}
+			reportPixelRate(b, sz.width*sz.height, time.Since(start))
+		})
+	}
+}
+
+func BenchmarkSetPixel(b *testing.B) {
+	for _, sz := range benchSizes {
+		b.Run(sz.name, func(b *testing.B) {
+			benchmarkSetPixel(b, sz.width, sz.height, false)
+		})
+	}
+}
+
+func BenchmarkSetPixelBlend(b *testing.B) {
+	for _, sz := range benchSizes {
+		b.Run(sz.name, func(b *testing.B) {
+			benchmarkSetPixel(b, sz.width, sz.height, true)
+		})
+	}
+}
+
+func benchmarkSetPixel(b *testing.B, width, height int, blend bool) {
+	rb := NewRasterBuffer(width, height).(*RasterBuffer)
+	rb.EnableAlphaBlending(blend)
+	rb.PixelColor.R = 255
+	rb.PixelColor.A = 127
+
+	b.ResetTimer()
+	start := time.Now()
+	for n := 0; n < b.N; n++ {
+		z := float32(n)
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				rb.SetPixel(x, y, z)
+			}
+		}
+	}
+	reportPixelRate(b, width*height, time.Since(start))
+}
+
+func BenchmarkDrawLine(b *testing.B) {
+	lines := []struct {
+		name           string
+		xP, yP, xQ, yQ int
+	}{
+		{"horizontal", 0, 240, 639, 240},
+		{"vertical", 320, 0, 320, 479},
+		{"shallow", 0, 0, 639, 200},
+		{"steep", 0, 0, 200, 479},
+		{"diagonal", 0, 0, 479, 479},
+	}
+
+	for _, l := range lines {
+		b.Run(l.name, func(b *testing.B) {
+			rb := NewRasterBuffer(640, 480).(*RasterBuffer)
+
+			dx := l.xQ - l.xP
+			dy := l.yQ - l.yP
+			if dx < 0 {
+				dx = -dx
+			}
+			if dy < 0 {
+				dy = -dy
+			}
+			pixels := dx + 1
+			if dy > dx {
+				pixels = dy + 1
+			}
+
+			b.ResetTimer()
+			start := time.Now()
+			for n := 0; n < b.N; n++ {
+				// DrawLine works with 1/z so a shrinking z is ever closer.
+				z := 1.0 / float32(n+1)
+				rb.DrawLine(l.xP, l.yP, l.xQ, l.yQ, z, z)
+			}
+			reportPixelRate(b, pixels, time.Since(start))
+		})
+	}
+}
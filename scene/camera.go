@@ -0,0 +1,26 @@
+package scene
+
+import "math"
+
+// Camera is a perspective projection. A camera node looks down its -Z
+// axis with +Y up.
+type Camera struct {
+	// FOV is the vertical field of view in radians
+	FOV float64
+	// Near is the distance of the near plane. Triangles crossing it
+	// are skipped.
+	Near float64
+}
+
+// NewCamera creates a camera with a 60 degree field of view.
+func NewCamera() *Camera {
+	c := new(Camera)
+	c.FOV = math.Pi / 3.0
+	c.Near = 0.1
+	return c
+}
+
+// focal returns the distance, in pixels, to a screen 'height' pixels tall.
+func (c *Camera) focal(height int) float64 {
+	return float64(height) / 2.0 / math.Tan(c.FOV/2.0)
+}
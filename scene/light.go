@@ -0,0 +1,30 @@
+package scene
+
+import "image/color"
+
+// LightKind selects how a light reaches surfaces.
+type LightKind int
+
+const (
+	// DirectionalLight shines along its node's -Z axis from infinitely far
+	DirectionalLight LightKind = iota
+	// PointLight shines in every direction from its node's origin
+	PointLight
+)
+
+// Light illuminates meshes with diffuse (Lambert) lighting. It is
+// placed and aimed by its node. There is no falloff with distance.
+type Light struct {
+	Kind      LightKind
+	Color     color.RGBA
+	Intensity float64
+}
+
+// NewLight creates a white light of full intensity.
+func NewLight(kind LightKind) *Light {
+	l := new(Light)
+	l.Kind = kind
+	l.Color = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	l.Intensity = 1.0
+	return l
+}
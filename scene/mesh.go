@@ -0,0 +1,52 @@
+package scene
+
+import (
+	"SoftRenderer/smath"
+	"image/color"
+)
+
+// Mesh is an indexed triangle list in its node's space. Front faces
+// wind counter-clockwise.
+type Mesh struct {
+	Vertices []smath.Vector3
+	// Indices holds three vertex indices per triangle
+	Indices []int
+
+	// Color is the base color, shaded by the scene's lights
+	Color color.RGBA
+	// EdgeColor is used when edges are drawn
+	EdgeColor color.RGBA
+}
+
+// NewMesh creates a white mesh with black edges.
+func NewMesh(vertices []smath.Vector3, indices []int) *Mesh {
+	m := new(Mesh)
+	m.Vertices = vertices
+	m.Indices = indices
+	m.Color = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	m.EdgeColor = color.RGBA{R: 0, G: 0, B: 0, A: 255}
+	return m
+}
+
+// NewBoxMesh creates a box centered on the origin.
+func NewBoxMesh(width, height, depth float64) *Mesh {
+	x, y, z := width/2.0, height/2.0, depth/2.0
+
+	// Corner i has x,y,z signs from bits 0,1,2
+	vertices := make([]smath.Vector3, 8)
+	for i := range vertices {
+		vertices[i].Set3Components(
+			float64(i&1*2-1)*x, float64(i>>1&1*2-1)*y, float64(i>>2&1*2-1)*z)
+	}
+
+	indices := []int{
+		0, 4, 6, 0, 6, 2, // -x
+		1, 3, 7, 1, 7, 5, // +x
+		0, 1, 5, 0, 5, 4, // -y
+		2, 6, 7, 2, 7, 3, // +y
+		0, 2, 3, 0, 3, 1, // -z
+		4, 5, 7, 4, 7, 6, // +z
+	}
+
+	return NewMesh(vertices, indices)
+}
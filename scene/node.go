@@ -0,0 +1,235 @@
+// Package scene organizes objects into a hierarchy of transformed nodes
+// and renders the meshes attached to them.
+package scene
+
+import (
+	"SoftRenderer/smath"
+	"errors"
+)
+
+// Node is an element of the scene graph. Its transform is a
+// translation, rotation and scale relative to its parent. A node can
+// carry a Mesh, Light and Camera, which all use the node's transform.
+type Node struct {
+	Name string
+	// Visible is false to skip the node and its children when rendering
+	Visible bool
+
+	Mesh   *Mesh
+	Light  *Light
+	Camera *Camera
+
+	position smath.Vector3
+	rotation smath.Quaternion
+	scale    smath.Vector3
+
+	parent   *Node
+	children []*Node
+
+	// local is rebuilt from position, rotation and scale when
+	// localDirty. world is rebuilt from the parent's world and local
+	// when worldDirty. A dirty node's descendants are always dirty.
+	local      smath.Matrix4
+	world      smath.Matrix4
+	localDirty bool
+	worldDirty bool
+}
+
+// NewNode creates a visible node with an identity transform.
+func NewNode(name string) *Node {
+	n := new(Node)
+	n.Name = name
+	n.Visible = true
+	n.rotation.W = 1.0
+	n.scale.Set3Components(1.0, 1.0, 1.0)
+	n.local.ToIdentity()
+	n.world.ToIdentity()
+	return n
+}
+
+// --------------------------------------------------------------------------
+// Transform
+// --------------------------------------------------------------------------
+
+// SetPosition sets the translation relative to the parent.
+func (n *Node) SetPosition(x, y, z float64) {
+	n.position.Set3Components(x, y, z)
+	n.invalidate()
+}
+
+// Position returns the translation relative to the parent.
+func (n *Node) Position() smath.Vector3 {
+	return n.position
+}
+
+// Translate moves the node by x,y,z in its parent's space.
+func (n *Node) Translate(x, y, z float64) {
+	n.SetPosition(n.position.X+x, n.position.Y+y, n.position.Z+z)
+}
+
+// SetRotation sets the rotation relative to the parent.
+func (n *Node) SetRotation(q smath.Quaternion) {
+	n.rotation = q
+	n.invalidate()
+}
+
+// Rotation returns the rotation relative to the parent.
+func (n *Node) Rotation() smath.Quaternion {
+	return n.rotation
+}
+
+// Rotate applies q after the current rotation, about the node's own axes.
+func (n *Node) Rotate(q smath.Quaternion) {
+	// Renormalize so repeated small rotations don't drift
+	n.SetRotation(smath.Unit(smath.Prod(n.rotation, q)))
+}
+
+// SetScale sets the scale along the node's axes.
+func (n *Node) SetScale(x, y, z float64) {
+	n.scale.Set3Components(x, y, z)
+	n.invalidate()
+}
+
+// Scale returns the scale along the node's axes.
+func (n *Node) Scale() smath.Vector3 {
+	return n.scale
+}
+
+// LocalMatrix returns the transform from the node's space to its parent's.
+func (n *Node) LocalMatrix() *smath.Matrix4 {
+	if n.localDirty {
+		var t, r, s, tr smath.Matrix4
+		t.SetTranslateByVector(&n.position)
+		r.SetRotationFromQuaternion(&n.rotation)
+		s.SetScale(&n.scale)
+
+		// local = T * R * S
+		smath.Multiply(&t, &r, &tr)
+		smath.Multiply(&tr, &s, &n.local)
+		n.localDirty = false
+	}
+	return &n.local
+}
+
+// WorldMatrix returns the transform from the node's space to the root's.
+// It is only recomputed after the node or an ancestor changed.
+func (n *Node) WorldMatrix() *smath.Matrix4 {
+	if n.worldDirty {
+		if n.parent == nil {
+			n.world.Set(n.LocalMatrix())
+		} else {
+			smath.Multiply(n.parent.WorldMatrix(), n.LocalMatrix(), &n.world)
+		}
+		n.worldDirty = false
+	}
+	return &n.world
+}
+
+// WorldPosition returns the node's origin in the root's space.
+func (n *Node) WorldPosition() smath.Vector3 {
+	var p smath.Vector3
+	n.WorldMatrix().GetTranslation(&p)
+	return p
+}
+
+// View returns the world to node transform, the view matrix when the
+// node carries a Camera. Scale is inverted along with everything else.
+func (n *Node) View(out *smath.Matrix4) bool {
+	return out.SetToAffineInverse(n.WorldMatrix())
+}
+
+// invalidate marks the local transform and every world transform that
+// depends on it as stale.
+func (n *Node) invalidate() {
+	n.localDirty = true
+	n.invalidateWorld()
+}
+
+func (n *Node) invalidateWorld() {
+	// Descendants of a dirty node are already dirty.
+	if n.worldDirty {
+		return
+	}
+	n.worldDirty = true
+	for _, c := range n.children {
+		c.invalidateWorld()
+	}
+}
+
+// --------------------------------------------------------------------------
+// Hierarchy
+// --------------------------------------------------------------------------
+
+// AddChild attaches c to n, detaching it from any previous parent. A
+// node can't be added beneath itself.
+func (n *Node) AddChild(c *Node) error {
+	for p := n; p != nil; p = p.parent {
+		if p == c {
+			return errors.New("scene: a node can't be its own descendant")
+		}
+	}
+
+	if c.parent != nil {
+		c.parent.RemoveChild(c)
+	}
+
+	c.parent = n
+	n.children = append(n.children, c)
+
+	// c's world is now relative to a different parent. Force the
+	// subtree dirty even if c was already marked.
+	c.worldDirty = false
+	c.invalidateWorld()
+
+	return nil
+}
+
+// RemoveChild detaches c from n. Returns false if c isn't n's child.
+func (n *Node) RemoveChild(c *Node) bool {
+	for i, child := range n.children {
+		if child == c {
+			copy(n.children[i:], n.children[i+1:])
+			n.children[len(n.children)-1] = nil
+			n.children = n.children[:len(n.children)-1]
+
+			c.parent = nil
+			c.worldDirty = false
+			c.invalidateWorld()
+			return true
+		}
+	}
+	return false
+}
+
+// Parent returns the node n is attached to, or nil for a root.
+func (n *Node) Parent() *Node {
+	return n.parent
+}
+
+// Children returns the nodes attached to n. The slice must not be modified.
+func (n *Node) Children() []*Node {
+	return n.children
+}
+
+// Find returns the first node named 'name' in n's subtree, depth first.
+func (n *Node) Find(name string) *Node {
+	var found *Node
+	n.Walk(func(c *Node) bool {
+		if found == nil && c.Name == name {
+			found = c
+		}
+		return found == nil
+	})
+	return found
+}
+
+// Walk calls visit for n and its descendants, depth first. Children of a
+// node are skipped when visit returns false for it.
+func (n *Node) Walk(visit func(n *Node) bool) {
+	if !visit(n) {
+		return
+	}
+	for _, c := range n.children {
+		c.Walk(visit)
+	}
+}
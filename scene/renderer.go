@@ -0,0 +1,234 @@
+package scene
+
+import (
+	"SoftRenderer/api"
+	graphics "SoftRenderer/graphcs"
+	"SoftRenderer/smath"
+	"image/color"
+	"math"
+)
+
+// DrawCall is a mesh to draw with the world transform of its node.
+type DrawCall struct {
+	Node  *Node
+	Mesh  *Mesh
+	World *smath.Matrix4
+}
+
+// worldLight is a light resolved into world space.
+type worldLight struct {
+	light *Light
+	// Direction toward a directional light, or position of a point light
+	v smath.Vector3
+}
+
+// Renderer draws the meshes of a scene into a raster buffer. Rendering
+// walks the graph collecting lights and submitting a DrawCall per mesh,
+// then draws the calls once every light is known.
+//
+// Depth is written as 1/distance so it stays perspective correct when
+// interpolated across a triangle. Larger is closer, as elsewhere.
+type Renderer struct {
+	// Mode is how triangles are rasterized
+	Mode api.RenderMode
+	// Ambient is the light every surface gets, 0 to 1
+	Ambient float64
+	// CullBackFaces skips triangles facing away from the camera
+	CullBackFaces bool
+
+	raster api.IRasterBuffer
+	tri    api.ITriangle
+
+	// Per frame state
+	view   smath.Matrix4
+	camera *Camera
+	lights []worldLight
+	calls  []DrawCall
+
+	// Scratch vertex buffers
+	world []smath.Vector3
+	eye   []smath.Vector3
+}
+
+// NewRenderer creates a renderer filling back face culled triangles.
+func NewRenderer() *Renderer {
+	r := new(Renderer)
+	r.Mode = api.RenderFill
+	r.Ambient = 0.2
+	r.CullBackFaces = true
+	r.tri = graphics.NewTriangle()
+	r.tri.SetDepthBias(0.001, 0.0)
+	return r
+}
+
+// Render draws the visible meshes beneath root as seen by the camera
+// carried by 'camera'.
+func (r *Renderer) Render(raster api.IRasterBuffer, root, camera *Node) {
+	var view smath.Matrix4
+	if camera.Camera == nil || !camera.View(&view) {
+		return
+	}
+	r.RenderView(raster, root, &view, camera.Camera)
+}
+
+// RenderView draws the visible meshes beneath root with an explicit
+// view matrix, for example from an api.ICamera controller.
+func (r *Renderer) RenderView(raster api.IRasterBuffer, root *Node, view *smath.Matrix4, camera *Camera) {
+	r.Begin(raster, view, camera)
+
+	root.Walk(func(n *Node) bool {
+		if !n.Visible {
+			return false
+		}
+		if n.Light != nil {
+			r.AddLight(n)
+		}
+		if n.Mesh != nil {
+			r.Submit(DrawCall{Node: n, Mesh: n.Mesh, World: n.WorldMatrix()})
+		}
+		return true
+	})
+
+	r.Flush()
+}
+
+// Begin starts a frame, discarding lights and draw calls of the last.
+func (r *Renderer) Begin(raster api.IRasterBuffer, view *smath.Matrix4, camera *Camera) {
+	r.raster = raster
+	r.view.Set(view)
+	r.camera = camera
+	r.lights = r.lights[:0]
+	r.calls = r.calls[:0]
+}
+
+// AddLight adds the light carried by n to the frame.
+func (r *Renderer) AddLight(n *Node) {
+	wl := worldLight{light: n.Light}
+
+	w := n.WorldMatrix()
+	if n.Light.Kind == DirectionalLight {
+		// The node's +Z axis, pointing back toward the light
+		wl.v.Set3Components(w.C(smath.M02), w.C(smath.M12), w.C(smath.M22))
+		wl.v.Normalize()
+	} else {
+		w.GetTranslation(&wl.v)
+	}
+
+	r.lights = append(r.lights, wl)
+}
+
+// Submit queues a mesh to draw at Flush.
+func (r *Renderer) Submit(call DrawCall) {
+	r.calls = append(r.calls, call)
+}
+
+// Flush draws the queued calls.
+func (r *Renderer) Flush() {
+	for _, call := range r.calls {
+		r.draw(&call)
+	}
+	r.calls = r.calls[:0]
+}
+
+// draw transforms a mesh to eye space and rasterizes its triangles.
+func (r *Renderer) draw(call *DrawCall) {
+	m := call.Mesh
+	n := len(m.Vertices)
+	if cap(r.world) < n {
+		r.world = make([]smath.Vector3, n)
+		r.eye = make([]smath.Vector3, n)
+	}
+	r.world = r.world[:n]
+	r.eye = r.eye[:n]
+
+	for i := range m.Vertices {
+		r.world[i].Set(&m.Vertices[i])
+		r.world[i].Mul(call.World)
+		r.eye[i].Set(&r.world[i])
+		r.eye[i].Mul(&r.view)
+	}
+
+	bounds := r.raster.Pixels().Bounds()
+	cx := float64(bounds.Dx()) / 2.0
+	cy := float64(bounds.Dy()) / 2.0
+	focal := r.camera.focal(bounds.Dy())
+	near := r.camera.Near
+
+	r.tri.SetEdgeColor(m.EdgeColor)
+	prev := r.raster.GetPixelColor()
+
+	for i := 0; i+2 < len(m.Indices); i += 3 {
+		a, b, c := &r.eye[m.Indices[i]], &r.eye[m.Indices[i+1]], &r.eye[m.Indices[i+2]]
+
+		// The camera looks down -Z. Skip triangles reaching behind
+		// the near plane.
+		if a.Z > -near || b.Z > -near || c.Z > -near {
+			continue
+		}
+
+		if r.CullBackFaces {
+			ab := smath.Vector3{X: b.X - a.X, Y: b.Y - a.Y, Z: b.Z - a.Z}
+			ac := smath.Vector3{X: c.X - a.X, Y: c.Y - a.Y, Z: c.Z - a.Z}
+			// Facing away when the normal points along the line of sight
+			if ab.Cross(&ac).Dot(a) >= 0.0 {
+				continue
+			}
+		}
+
+		wa, wb, wc := &r.world[m.Indices[i]], &r.world[m.Indices[i+1]], &r.world[m.Indices[i+2]]
+		r.raster.SetPixelColor(r.shade(m.Color, wa, wb, wc))
+
+		// Screen y is downward
+		r.tri.SetWithZ(
+			int(cx-focal*a.X/a.Z), int(cy+focal*a.Y/a.Z), float32(-1.0/a.Z),
+			int(cx-focal*b.X/b.Z), int(cy+focal*b.Y/b.Z), float32(-1.0/b.Z),
+			int(cx-focal*c.X/c.Z), int(cy+focal*c.Y/c.Z), float32(-1.0/c.Z))
+		r.tri.Render(r.raster, r.Mode)
+	}
+
+	r.raster.SetPixelColor(prev)
+}
+
+// shade returns the flat shaded color of the world space triangle a,b,c.
+func (r *Renderer) shade(base color.RGBA, a, b, c *smath.Vector3) color.RGBA {
+	normal := smath.Vector3{X: b.X - a.X, Y: b.Y - a.Y, Z: b.Z - a.Z}
+	ac := smath.Vector3{X: c.X - a.X, Y: c.Y - a.Y, Z: c.Z - a.Z}
+	normal.Cross(&ac)
+	if normal.LengthSquared() == 0.0 {
+		// Degenerate, it has no facing
+		normal.Set3Components(0.0, 0.0, 0.0)
+	} else {
+		normal.Normalize()
+	}
+
+	center := smath.Vector3{X: (a.X + b.X + c.X) / 3.0, Y: (a.Y + b.Y + c.Y) / 3.0, Z: (a.Z + b.Z + c.Z) / 3.0}
+
+	lr, lg, lb := r.Ambient, r.Ambient, r.Ambient
+	for i := range r.lights {
+		wl := &r.lights[i]
+
+		toLight := wl.v
+		if wl.light.Kind == PointLight {
+			toLight.Sub(&center)
+			if toLight.LengthSquared() == 0.0 {
+				continue
+			}
+			toLight.Normalize()
+		}
+
+		d := normal.Dot(&toLight) * wl.light.Intensity
+		if d <= 0.0 {
+			continue
+		}
+		lr += d * float64(wl.light.Color.R) / 255.0
+		lg += d * float64(wl.light.Color.G) / 255.0
+		lb += d * float64(wl.light.Color.B) / 255.0
+	}
+
+	return color.RGBA{
+		R: uint8(math.Min(float64(base.R)*lr, 255.0)),
+		G: uint8(math.Min(float64(base.G)*lg, 255.0)),
+		B: uint8(math.Min(float64(base.B)*lb, 255.0)),
+		A: base.A,
+	}
+}
@@ -0,0 +1,265 @@
+package scene
+
+import (
+	"SoftRenderer/smath"
+	"math"
+	"testing"
+)
+
+const tolerance = 1e-9
+
+// axisAngle returns a rotation of 'angle' radians about the unit axis x,y,z.
+func axisAngle(angle, x, y, z float64) smath.Quaternion {
+	s := math.Sin(angle / 2.0)
+	return smath.Quaternion{W: math.Cos(angle / 2.0), X: x * s, Y: y * s, Z: z * s}
+}
+
+func nearVector(a smath.Vector3, x, y, z float64) bool {
+	return math.Abs(a.X-x) < tolerance && math.Abs(a.Y-y) < tolerance && math.Abs(a.Z-z) < tolerance
+}
+
+func isIdentity(m *smath.Matrix4) bool {
+	for row := 0; row < 4; row++ {
+		for col := 0; col < 4; col++ {
+			want := 0.0
+			if row == col {
+				want = 1.0
+			}
+			if math.Abs(m.C(row*4+col)-want) > tolerance {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// checkDirty fails if a dirty node has a clean descendant.
+func checkDirty(t *testing.T, n *Node) {
+	t.Helper()
+	n.Walk(func(c *Node) bool {
+		if c.parent != nil && c.parent.worldDirty && !c.worldDirty {
+			t.Errorf("%s is clean beneath dirty %s", c.Name, c.parent.Name)
+		}
+		return true
+	})
+}
+
+func TestWorldPosition(t *testing.T) {
+	tests := []struct {
+		name     string
+		position smath.Vector3
+		rotation smath.Quaternion
+		scale    smath.Vector3
+		// Child at (1,0,0) in the parent's space
+		want smath.Vector3
+	}{
+		{"identity", smath.Vector3{}, smath.Quaternion{W: 1}, smath.Vector3{X: 1, Y: 1, Z: 1}, smath.Vector3{X: 1}},
+		{"translated", smath.Vector3{X: 2, Y: 3, Z: 4}, smath.Quaternion{W: 1}, smath.Vector3{X: 1, Y: 1, Z: 1}, smath.Vector3{X: 3, Y: 3, Z: 4}},
+		{"yawed", smath.Vector3{}, axisAngle(math.Pi/2, 0, 1, 0), smath.Vector3{X: 1, Y: 1, Z: 1}, smath.Vector3{Z: -1}},
+		{"scaled", smath.Vector3{}, smath.Quaternion{W: 1}, smath.Vector3{X: 2, Y: 1, Z: 1}, smath.Vector3{X: 2}},
+		{"all", smath.Vector3{Y: 1}, axisAngle(math.Pi/2, 0, 0, 1), smath.Vector3{X: 3, Y: 3, Z: 3}, smath.Vector3{Y: 4}},
+	}
+
+	for _, tt := range tests {
+		parent := NewNode("parent")
+		parent.SetPosition(tt.position.X, tt.position.Y, tt.position.Z)
+		parent.SetRotation(tt.rotation)
+		parent.SetScale(tt.scale.X, tt.scale.Y, tt.scale.Z)
+
+		child := NewNode("child")
+		child.SetPosition(1, 0, 0)
+		parent.AddChild(child)
+
+		if got := child.WorldPosition(); !nearVector(got, tt.want.X, tt.want.Y, tt.want.Z) {
+			t.Errorf("%s: world position %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWorldMatrixCaching(t *testing.T) {
+	root := NewNode("root")
+	mid := NewNode("mid")
+	leaf := NewNode("leaf")
+	root.AddChild(mid)
+	mid.AddChild(leaf)
+	leaf.SetPosition(0, 0, 1)
+
+	leaf.WorldMatrix()
+	if root.worldDirty || mid.worldDirty || leaf.worldDirty {
+		t.Fatal("WorldMatrix left an ancestor dirty")
+	}
+
+	// A clean leaf keeps its matrix
+	before := *leaf.WorldMatrix()
+	if *leaf.WorldMatrix() != before {
+		t.Error("clean WorldMatrix changed")
+	}
+
+	// Moving the root dirties the whole chain
+	root.Translate(5, 0, 0)
+	if !mid.worldDirty || !leaf.worldDirty {
+		t.Error("moving the root didn't dirty its descendants")
+	}
+	checkDirty(t, root)
+
+	if got := leaf.WorldPosition(); !nearVector(got, 5, 0, 1) {
+		t.Errorf("leaf at %v after moving the root, want (5,0,1)", got)
+	}
+
+	// Marking a dirty node again must still reach a clean child.
+	mid.SetPosition(0, 1, 0)
+	root.WorldMatrix()
+	mid.SetPosition(0, 2, 0)
+	checkDirty(t, root)
+	if got := leaf.WorldPosition(); !nearVector(got, 5, 2, 1) {
+		t.Errorf("leaf at %v after moving mid, want (5,2,1)", got)
+	}
+}
+
+func TestReparent(t *testing.T) {
+	tests := []struct {
+		name string
+		// Reparents 'node' and returns its expected world position
+		move func(a, b, node *Node) smath.Vector3
+	}{
+		{"to sibling", func(a, b, node *Node) smath.Vector3 {
+			b.AddChild(node)
+			return smath.Vector3{X: 1, Y: 10, Z: 0}
+		}},
+		{"to root", func(a, b, node *Node) smath.Vector3 {
+			a.RemoveChild(node)
+			return smath.Vector3{X: 1}
+		}},
+		{"beneath rotated", func(a, b, node *Node) smath.Vector3 {
+			b.SetRotation(axisAngle(math.Pi, 0, 0, 1))
+			b.AddChild(node)
+			return smath.Vector3{X: -1, Y: 10}
+		}},
+		{"back again", func(a, b, node *Node) smath.Vector3 {
+			b.AddChild(node)
+			node.WorldMatrix()
+			a.AddChild(node)
+			return smath.Vector3{X: 6}
+		}},
+	}
+
+	for _, tt := range tests {
+		root := NewNode("root")
+		a := NewNode("a")
+		a.SetPosition(5, 0, 0)
+		b := NewNode("b")
+		b.SetPosition(0, 10, 0)
+		root.AddChild(a)
+		root.AddChild(b)
+
+		node := NewNode("node")
+		node.SetPosition(1, 0, 0)
+		a.AddChild(node)
+		child := NewNode("child")
+		node.AddChild(child)
+
+		// Cache every world matrix so reparenting has to dirty them
+		root.Walk(func(n *Node) bool {
+			n.WorldMatrix()
+			return true
+		})
+
+		want := tt.move(a, b, node)
+		checkDirty(t, root)
+		checkDirty(t, node)
+
+		if got := node.WorldPosition(); !nearVector(got, want.X, want.Y, want.Z) {
+			t.Errorf("%s: node at %v, want %v", tt.name, got, want)
+		}
+		if got := child.WorldPosition(); !nearVector(got, want.X, want.Y, want.Z) {
+			t.Errorf("%s: child at %v, want %v", tt.name, got, want)
+		}
+
+		// The node is only ever the child of one parent
+		count := 0
+		root.Walk(func(n *Node) bool {
+			if n == node {
+				count++
+			}
+			return true
+		})
+		if node.Parent() != nil && count != 1 {
+			t.Errorf("%s: node appears %d times in the graph", tt.name, count)
+		}
+	}
+}
+
+func TestAddChildRejectsCycles(t *testing.T) {
+	a := NewNode("a")
+	b := NewNode("b")
+	c := NewNode("c")
+	a.AddChild(b)
+	b.AddChild(c)
+
+	for _, tt := range []struct {
+		name         string
+		parent, node *Node
+	}{
+		{"self", a, a},
+		{"parent", b, a},
+		{"grandparent", c, a},
+	} {
+		if err := tt.parent.AddChild(tt.node); err == nil {
+			t.Errorf("%s: AddChild accepted a cycle", tt.name)
+		}
+	}
+
+	if a.Parent() != nil || b.Parent() != a || c.Parent() != b {
+		t.Error("a rejected AddChild changed the hierarchy")
+	}
+}
+
+func TestViewInvertsWorld(t *testing.T) {
+	tests := []struct {
+		name     string
+		position smath.Vector3
+		rotation smath.Quaternion
+		scale    smath.Vector3
+	}{
+		{"identity", smath.Vector3{}, smath.Quaternion{W: 1}, smath.Vector3{X: 1, Y: 1, Z: 1}},
+		{"translated", smath.Vector3{X: -3, Y: 2, Z: 7}, smath.Quaternion{W: 1}, smath.Vector3{X: 1, Y: 1, Z: 1}},
+		{"rotated", smath.Vector3{}, axisAngle(1.0, 0.6, 0.0, 0.8), smath.Vector3{X: 1, Y: 1, Z: 1}},
+		{"non-uniform", smath.Vector3{X: 1}, axisAngle(0.3, 0, 1, 0), smath.Vector3{X: 2, Y: 0.5, Z: 3}},
+		{"small scale", smath.Vector3{Z: 1}, smath.Quaternion{W: 1}, smath.Vector3{X: 1e-3, Y: 1e-3, Z: 1e-3}},
+	}
+
+	for _, tt := range tests {
+		parent := NewNode("parent")
+		parent.SetPosition(0, 4, 0)
+		parent.SetRotation(axisAngle(0.7, 1, 0, 0))
+
+		n := NewNode("camera")
+		n.SetPosition(tt.position.X, tt.position.Y, tt.position.Z)
+		n.SetRotation(tt.rotation)
+		n.SetScale(tt.scale.X, tt.scale.Y, tt.scale.Z)
+		parent.AddChild(n)
+
+		var view, product smath.Matrix4
+		if !n.View(&view) {
+			t.Errorf("%s: View failed", tt.name)
+			continue
+		}
+
+		smath.Multiply(&view, n.WorldMatrix(), &product)
+		if !isIdentity(&product) {
+			t.Errorf("%s: view * world isn't identity\n%v", tt.name, product)
+		}
+		smath.Multiply(n.WorldMatrix(), &view, &product)
+		if !isIdentity(&product) {
+			t.Errorf("%s: world * view isn't identity\n%v", tt.name, product)
+		}
+	}
+}
+
+func TestSetToAffineInverseSingular(t *testing.T) {
+	var m, inv smath.Matrix4
+	m.SetScale3Comp(1, 0, 1)
+	if inv.SetToAffineInverse(&m) {
+		t.Error("inverted a matrix with a zero scale")
+	}
+}
@@ -208,6 +208,26 @@ func (m *Matrix4) RotateBy(angle float64) *Matrix4 {
 	return m
 }
 
+// SetRotationFromQuaternion sets this matrix to the rotation represented by
+// the quaternion 'q'. Any translation or scale is removed.
+func (m *Matrix4) SetRotationFromQuaternion(q *Quaternion) *Matrix4 {
+	m.ToIdentity()
+
+	r := RotMat(*q)
+
+	m.e[M00] = r[0][0]
+	m.e[M01] = r[0][1]
+	m.e[M02] = r[0][2]
+	m.e[M10] = r[1][0]
+	m.e[M11] = r[1][1]
+	m.e[M12] = r[1][2]
+	m.e[M20] = r[2][0]
+	m.e[M21] = r[2][1]
+	m.e[M22] = r[2][2]
+
+	return m
+}
+
 // --------------------------------------------------------------------------
 // Scale
 // --------------------------------------------------------------------------
@@ -457,6 +477,50 @@ func MultiplyIntoA(a, b *Matrix4) {
 	a.e[M33] = temp.e[M33]
 }
 
+// SetToAffineInverse sets this matrix to the inverse of 'src', which must
+// be an affine transform (rotation, scale and translation). Returns false,
+// leaving this matrix unchanged, if 'src' is singular.
+func (m *Matrix4) SetToAffineInverse(src *Matrix4) bool {
+	a, b, c := src.e[M00], src.e[M01], src.e[M02]
+	d, e, f := src.e[M10], src.e[M11], src.e[M12]
+	g, h, i := src.e[M20], src.e[M21], src.e[M22]
+
+	// Cofactors of the upper 3x3
+	c00 := e*i - f*h
+	c01 := f*g - d*i
+	c02 := d*h - e*g
+
+	det := a*c00 + b*c01 + c*c02
+	if det == 0.0 {
+		return false
+	}
+	inv := 1.0 / det
+
+	tx, ty, tz := src.e[M03], src.e[M13], src.e[M23]
+
+	m.e[M00] = c00 * inv
+	m.e[M01] = (c*h - b*i) * inv
+	m.e[M02] = (b*f - c*e) * inv
+	m.e[M10] = c01 * inv
+	m.e[M11] = (a*i - c*g) * inv
+	m.e[M12] = (c*d - a*f) * inv
+	m.e[M20] = c02 * inv
+	m.e[M21] = (b*g - a*h) * inv
+	m.e[M22] = (a*e - b*d) * inv
+
+	// The translation is the original, moved back through the inverse
+	m.e[M03] = -(m.e[M00]*tx + m.e[M01]*ty + m.e[M02]*tz)
+	m.e[M13] = -(m.e[M10]*tx + m.e[M11]*ty + m.e[M12]*tz)
+	m.e[M23] = -(m.e[M20]*tx + m.e[M21]*ty + m.e[M22]*tz)
+
+	m.e[M30] = 0.0
+	m.e[M31] = 0.0
+	m.e[M32] = 0.0
+	m.e[M33] = 1.0
+
+	return true
+}
+
 // PostTranslate postmultiplies this matrix by a translation matrix.
 // Postmultiplication is also used by OpenGL ES.
 func (m *Matrix4) PostTranslate(tx, ty, tz float64) *Matrix4 {
@@ -519,6 +583,62 @@ func (m *Matrix4) SetToOrtho(left, right, bottom, top, near, far float64) *Matri
 	return m
 }
 
+// --------------------------------------------------------------------------
+// Views
+// --------------------------------------------------------------------------
+
+// SetToLookAt sets the matrix to a view transform for a camera at 'eye'
+// looking at 'target'. The camera looks down its -Z axis with 'up'
+// roughly along +Y.
+func (m *Matrix4) SetToLookAt(eye, target, up *Vector3) *Matrix4 {
+	m.ToIdentity()
+
+	// forward
+	fx := target.X - eye.X
+	fy := target.Y - eye.Y
+	fz := target.Z - eye.Z
+	l := Length(fx, fy, fz)
+	if l == 0 {
+		return m
+	}
+	fx /= l
+	fy /= l
+	fz /= l
+
+	// side = forward x up
+	sx := fy*up.Z - fz*up.Y
+	sy := fz*up.X - fx*up.Z
+	sz := fx*up.Y - fy*up.X
+	l = Length(sx, sy, sz)
+	if l == 0 {
+		// Looking straight along 'up'
+		return m
+	}
+	sx /= l
+	sy /= l
+	sz /= l
+
+	// true up = side x forward
+	ux := sy*fz - sz*fy
+	uy := sz*fx - sx*fz
+	uz := sx*fy - sy*fx
+
+	m.e[M00] = sx
+	m.e[M01] = sy
+	m.e[M02] = sz
+	m.e[M03] = -Dot(sx, sy, sz, eye.X, eye.Y, eye.Z)
+	m.e[M10] = ux
+	m.e[M11] = uy
+	m.e[M12] = uz
+	m.e[M13] = -Dot(ux, uy, uz, eye.X, eye.Y, eye.Z)
+	m.e[M20] = -fx
+	m.e[M21] = -fy
+	m.e[M22] = -fz
+	m.e[M23] = Dot(fx, fy, fz, eye.X, eye.Y, eye.Z)
+
+	return m
+}
+
 // --------------------------------------------------------------------------
 // Misc
 // --------------------------------------------------------------------------
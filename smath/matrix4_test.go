@@ -0,0 +1,62 @@
+package smath
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSetToLookAt(t *testing.T) {
+	tests := []struct {
+		name            string
+		eye, target, up Vector3
+		// Row major
+		want [4][4]float64
+	}{
+		{"down -z", Vector3{Z: 5}, Vector3{}, Vector3{Y: 1}, [4][4]float64{
+			{1, 0, 0, 0},
+			{0, 1, 0, 0},
+			{0, 0, 1, -5},
+			{0, 0, 0, 1},
+		}},
+		// Looking down -x the camera's right is -z
+		{"down -x", Vector3{X: 3}, Vector3{}, Vector3{Y: 1}, [4][4]float64{
+			{0, 0, -1, 0},
+			{0, 1, 0, 0},
+			{1, 0, 0, -3},
+			{0, 0, 0, 1},
+		}},
+		// 'up' is made perpendicular to the view direction
+		{"tilted up", Vector3{X: 1, Y: 2, Z: 5}, Vector3{X: 1, Y: 2}, Vector3{Y: 1, Z: 1}, [4][4]float64{
+			{1, 0, 0, -1},
+			{0, 1, 0, -2},
+			{0, 0, 1, -5},
+			{0, 0, 0, 1},
+		}},
+		// Degenerate views are left as identity
+		{"at the target", Vector3{X: 1}, Vector3{X: 1}, Vector3{Y: 1}, [4][4]float64{
+			{1, 0, 0, 0},
+			{0, 1, 0, 0},
+			{0, 0, 1, 0},
+			{0, 0, 0, 1},
+		}},
+		{"along up", Vector3{}, Vector3{Y: 2}, Vector3{Y: 1}, [4][4]float64{
+			{1, 0, 0, 0},
+			{0, 1, 0, 0},
+			{0, 0, 1, 0},
+			{0, 0, 0, 1},
+		}},
+	}
+
+	for _, tt := range tests {
+		var m Matrix4
+		m.SetToLookAt(&tt.eye, &tt.target, &tt.up)
+		for row := 0; row < 4; row++ {
+			for col := 0; col < 4; col++ {
+				// Cells are stored column major, see M01
+				if got := m.C(col*4 + row); math.Abs(got-tt.want[row][col]) > 1e-9 {
+					t.Errorf("%s: cell %d,%d is %v, want %v\n%v", tt.name, row, col, got, tt.want[row][col], m)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package surface
+
+import (
+	"SoftRenderer/imaging"
+	"fmt"
+	"image"
+	"log"
+	"path/filepath"
+	"time"
+	"unsafe"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// capture is a pending request to save upcoming frames.
+type capture struct {
+	remaining  int
+	index      int
+	sequence   bool
+	includeHUD bool
+	base       string
+}
+
+// Screenshot saves the next frame to a timestamped image in
+// Config.ScreenshotDir, in Config.ScreenshotFormat. With includeHUD the image is the window's
+// contents, text and profiler included, otherwise just the raster.
+func (ws *WindowSurface) Screenshot(includeHUD bool) {
+	ws.startCapture(1, false, includeHUD)
+}
+
+// CaptureFrames saves each of the next n frames as a numbered image,
+// for example to assemble into a video.
+func (ws *WindowSurface) CaptureFrames(n int, includeHUD bool) {
+	ws.startCapture(n, true, includeHUD)
+}
+
+// StartRecording begins collecting raster frames for an animated
+// GIF. It does nothing if a recording is in progress.
+func (ws *WindowSurface) StartRecording() {
+	if ws.recorder != nil {
+		return
+	}
+	ws.recorder = imaging.NewGIFRecorder()
+	log.Println("Recording started")
+}
+
+// StopRecording ends the recording and saves it to a timestamped GIF
+// in Config.ScreenshotDir. Encoding happens in the background.
+func (ws *WindowSurface) StopRecording() {
+	r := ws.recorder
+	if r == nil {
+		return
+	}
+	ws.recorder = nil
+
+	path := filepath.Join(ws.config.ScreenshotDir, "recording-"+time.Now().Format("20060102-150405.000")+".gif")
+
+	ws.writes.Add(1)
+	go func() {
+		defer ws.writes.Done()
+		err := r.Save(path)
+		if err != nil {
+			log.Println("recording:", err)
+			return
+		}
+		log.Printf("Saved %s (%d frames, %.1fs)", path, r.Len(), r.Duration())
+	}()
+}
+
+// Recording reports whether a GIF recording is in progress.
+func (ws *WindowSurface) Recording() bool {
+	return ws.recorder != nil
+}
+
+func (ws *WindowSurface) startCapture(n int, sequence, includeHUD bool) {
+	ws.capture = capture{
+		remaining:  n,
+		sequence:   sequence,
+		includeHUD: includeHUD,
+		base:       filepath.Join(ws.config.ScreenshotDir, "screenshot-"+time.Now().Format("20060102-150405.000")),
+	}
+}
+
+// captureFrame saves the frame being drawn if a capture is pending.
+// It must run before Present, after which the back buffer's
+// contents are undefined.
+func (ws *WindowSurface) captureFrame() {
+	c := &ws.capture
+	if c.remaining <= 0 {
+		return
+	}
+
+	var img *image.RGBA
+	var err error
+	if c.includeHUD {
+		img, err = ws.readWindow()
+		if err != nil {
+			log.Println("screenshot:", err)
+			c.remaining = 0
+			return
+		}
+	} else {
+		img = opaqueCopy(ws.frame)
+	}
+
+	ext := "." + ws.config.ScreenshotFormat
+	path := c.base + ext
+	if c.sequence {
+		path = fmt.Sprintf("%s-%04d%s", c.base, c.index, ext)
+	}
+	c.index++
+	c.remaining--
+
+	// Encoding is slow, so it is done off the frame loop. Close
+	// waits for pending writes.
+	ws.writes.Add(1)
+	go func() {
+		defer ws.writes.Done()
+		err := imaging.Save(path, img)
+		if err != nil {
+			log.Println("screenshot:", err)
+			return
+		}
+		log.Println("Saved", path)
+	}()
+}
+
+// SaveDepth writes the depth buffer of the last rendered frame to
+// path as PFM, or as headerless float32 for raw and f32 extensions.
+func (ws *WindowSurface) SaveDepth(path string) error {
+	b := ws.rasterBuffer.Pixels().Bounds()
+	return imaging.SaveDepth(path, ws.rasterBuffer.Depth(), b.Dx(), b.Dy())
+}
+
+// saveDepthShot saves the depth buffer to a timestamped PFM in
+// Config.ScreenshotDir.
+func (ws *WindowSurface) saveDepthShot() {
+	path := filepath.Join(ws.config.ScreenshotDir, "depth-"+time.Now().Format("20060102-150405.000")+".pfm")
+	err := ws.SaveDepth(path)
+	if err != nil {
+		log.Println("depth:", err)
+		return
+	}
+	log.Println("Saved", path)
+}
+
+// recordFrame adds the shown raster to the recording, if any. dt is how
+// long the frame is shown for. A full recording is stopped and saved.
+func (ws *WindowSurface) recordFrame(dt float64) {
+	if ws.recorder == nil {
+		return
+	}
+
+	ws.recorder.AddFrame(ws.frame, dt)
+	if ws.recorder.Full() {
+		log.Printf("Recording reached %d frames", ws.recorder.Len())
+		ws.StopRecording()
+	}
+}
+
+// readWindow reads back the renderer's output, limited to the
+// letterboxed area the raster is shown in.
+func (ws *WindowSurface) readWindow() (*image.RGBA, error) {
+	ow, oh, err := ws.renderer.GetOutputSize()
+	if err != nil {
+		return nil, err
+	}
+
+	// ReadPixels reads the viewport, in output pixels. The buffer is
+	// sized to the whole output so a rounding difference can't
+	// overrun it.
+	vp := ws.renderer.GetViewport()
+	sx, sy := ws.renderer.GetScale()
+	w := int(float32(vp.W) * sx)
+	h := int(float32(vp.H) * sy)
+	if w <= 0 || w > int(ow) {
+		w = int(ow)
+	}
+	if h <= 0 || h > int(oh) {
+		h = int(oh)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, int(ow), int(oh)))
+	err = ws.renderer.ReadPixels(nil, sdl.PIXELFORMAT_ABGR8888, unsafe.Pointer(&img.Pix[0]), img.Stride)
+	if err != nil {
+		return nil, err
+	}
+
+	img = img.SubImage(image.Rect(0, 0, w, h)).(*image.RGBA)
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 255
+	}
+
+	return img, nil
+}
+
+// opaqueCopy copies src with alpha forced to 255, matching how the
+// raster is displayed.
+func opaqueCopy(src *image.RGBA) *image.RGBA {
+	img := image.NewRGBA(src.Rect)
+	copy(img.Pix, src.Pix)
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 255
+	}
+	return img
+}
@@ -0,0 +1,46 @@
+package surface
+
+import (
+	"SoftRenderer/imaging"
+	"image"
+)
+
+// ShowDepth switches the window between the raster's colors and a
+// visualization of its depth buffer. F4 toggles it.
+func (ws *WindowSurface) ShowDepth(show bool) {
+	ws.showDepth = show
+}
+
+// DepthView returns the settings the depth buffer is visualized with.
+func (ws *WindowSurface) DepthView() *imaging.DepthView {
+	return ws.depthView
+}
+
+// SaveDepthImage writes the last frame's depth visualization to path
+// in the format given by its extension.
+func (ws *WindowSurface) SaveDepthImage(path string) error {
+	return imaging.Save(path, ws.depthImage())
+}
+
+// depthImage draws the depth visualization of the raster, reusing
+// the image while the raster's size is unchanged.
+func (ws *WindowSurface) depthImage() *image.RGBA {
+	b := ws.rasterBuffer.Pixels().Bounds()
+	if ws.depthPixels == nil || ws.depthPixels.Rect != b {
+		ws.depthPixels = image.NewRGBA(b)
+	}
+
+	ws.depthView.Clear = ws.rasterBuffer.GetClearDepth()
+	ws.depthView.Draw(ws.depthPixels, ws.rasterBuffer.Depth())
+
+	return ws.depthPixels
+}
+
+// shown returns the image displayed this frame: the raster, or its
+// depth when ShowDepth is on.
+func (ws *WindowSurface) shown() *image.RGBA {
+	if ws.showDepth {
+		return ws.depthImage()
+	}
+	return ws.rasterBuffer.Pixels()
+}
@@ -4,27 +4,37 @@ import (
 	"github.com/veandco/go-sdl2/sdl"
 )
 
-type dChar struct {
-	width, height int32
-	txt           *Text
+const (
+	atlasInitialSize = 256
+	atlasMaxSize     = 4096
+	atlasPadding     = 1
+)
+
+// glyph is a rune's cell in the atlas.
+type glyph struct {
+	src     sdl.Rect
+	advance int32
 }
 
 // DynaText represents dynamically changing text.
-// The text is broken down into characters and each
-// character is rendered using an existing character atlas.
-// This is a base class for dynamic text.
+// Each rune is rendered once, on first use, into a single atlas
+// texture. Drawing is then a map lookup per rune and a run of
+// copies from that one texture, tinted with Color.
 type DynaText struct {
 	nFont    *Font
 	renderer *sdl.Renderer
 
-	// Char textures
-	txtChars []*dChar
-
-	surface *sdl.Surface
+	// Atlas of rendered runes
+	glyphs  map[rune]*glyph
+	kerning map[[2]rune]int32
 	texture *sdl.Texture
-	Color   sdl.Color
+	size    int32
+
+	// Shelf packer cursor
+	penX, penY int32
+	shelfH     int32
 
-	bounds sdl.Rect
+	Color sdl.Color
 
 	X      int32
 	Y      int32
@@ -45,58 +55,291 @@ func NewDynaText(font *Font, renderer *sdl.Renderer, color sdl.Color) *DynaText
 	return t
 }
 
-// Initialize sets up Text based on TextPath
+// Initialize creates the atlas and preloads printable ASCII.
 func (t *DynaText) initialize() error {
-	// Iterate through each character generating a texture.
-	chars := "0123456789-:.,<> ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
-	t.txtChars = make([]*dChar, len(chars))
+	t.glyphs = make(map[rune]*glyph)
+	t.kerning = make(map[[2]rune]int32)
+	t.Height = int32(t.nFont.font.Height())
 
-	i := 0
-	for _, c := range chars {
-		txtC := NewText(t.nFont, t.renderer)
-		txtC.SetText(string(c), t.Color)
-		t.txtChars[i] = new(dChar)
-		t.txtChars[i].width = txtC.Bounds.W
-		t.txtChars[i].height = txtC.Bounds.H
-		t.txtChars[i].txt = txtC
-		i++
+	err := t.allocate(atlasInitialSize)
+	if err != nil {
+		return err
+	}
+
+	for c := rune(' '); c <= '~'; c++ {
+		t.lookup(c)
 	}
 
 	return nil
 }
 
-// Draw renders text
-func (t *DynaText) Draw(text string) {
-	x := t.X
+// allocate replaces the atlas texture with an empty one of the given size.
+func (t *DynaText) allocate(size int32) error {
+	texture, err := t.renderer.CreateTexture(sdl.PIXELFORMAT_ABGR8888, sdl.TEXTUREACCESS_STREAMING, size, size)
+	if err != nil {
+		return err
+	}
+
+	// Clear the new atlas so unused cells are transparent.
+	pixels := make([]byte, size*size*4)
+	texture.Update(nil, pixels, int(size*4))
+	texture.SetBlendMode(sdl.BLENDMODE_BLEND)
+
+	if t.texture != nil {
+		t.texture.Destroy()
+	}
+
+	t.texture = texture
+	t.size = size
+	t.penX, t.penY, t.shelfH = 0, 0, 0
+
+	return nil
+}
+
+// lookup returns the glyph for c, rendering it into the atlas
+// the first time it is seen. Runes the font can't render are
+// cached as empty glyphs so they are only attempted once.
+func (t *DynaText) lookup(c rune) *glyph {
+	if g, ok := t.glyphs[c]; ok {
+		return g
+	}
+
+	g := new(glyph)
+	t.glyphs[c] = g
+	t.render(c, g)
+
+	return g
+}
+
+// render rasterizes c and uploads it to the next free cell of the
+// atlas, growing the atlas when it is full.
+func (t *DynaText) render(c rune, g *glyph) {
+	surface := t.rasterize(c, g)
+	if surface == nil {
+		return
+	}
+	defer surface.Free()
+
+	for !t.upload(g, surface) {
+		if !t.grow() {
+			return
+		}
+	}
+}
+
+// rasterize renders c in white (Color is applied as a color mod) and
+// sets its advance. The caller frees the returned surface.
+func (t *DynaText) rasterize(c rune, g *glyph) *sdl.Surface {
+	g.advance = 0
+	// GlyphMetrics takes a 16 bit character, so runes beyond the
+	// basic plane would get another rune's metrics.
+	if c <= 0xFFFF {
+		if metrics, err := t.nFont.font.GlyphMetrics(c); err == nil {
+			g.advance = int32(metrics.Advance)
+		}
+	}
+
+	surface, err := t.nFont.font.RenderUTF8Blended(string(c), sdl.Color{R: 255, G: 255, B: 255, A: 255})
+	if err != nil {
+		return nil
+	}
+	defer surface.Free()
+
+	converted, err := surface.ConvertFormat(sdl.PIXELFORMAT_ABGR8888, 0)
+	if err != nil {
+		return nil
+	}
+
+	if g.advance == 0 {
+		g.advance = converted.W
+	}
+
+	return converted
+}
+
+// upload copies a rasterized glyph into a free cell. It returns false
+// if the atlas is full.
+func (t *DynaText) upload(g *glyph, surface *sdl.Surface) bool {
+	if t.texture == nil {
+		return false
+	}
+
+	cell, ok := t.place(surface.W, surface.H)
+	if !ok {
+		return false
+	}
+
+	if t.texture.Update(&cell, surface.Pixels(), int(surface.Pitch)) != nil {
+		// Leave the glyph undrawn, the cell is lost
+		return true
+	}
+
+	g.src = cell
+	return true
+}
+
+// place reserves a w x h cell using shelf packing. It returns false if
+// the atlas has no room left.
+func (t *DynaText) place(w, h int32) (sdl.Rect, bool) {
+	if t.penX+w+atlasPadding > t.size {
+		// Start a new shelf
+		t.penX = 0
+		t.penY += t.shelfH
+		t.shelfH = 0
+	}
+
+	if w+atlasPadding > t.size || t.penY+h+atlasPadding > t.size {
+		return sdl.Rect{}, false
+	}
+
+	cell := sdl.Rect{X: t.penX, Y: t.penY, W: w, H: h}
+
+	t.penX += w + atlasPadding
+	if h+atlasPadding > t.shelfH {
+		t.shelfH = h + atlasPadding
+	}
+
+	return cell, true
+}
+
+// grow doubles the atlas, until the glyphs already in it fit again,
+// and repacks them. The glyph being placed has no cell yet, so it is
+// left for the caller to upload.
+func (t *DynaText) grow() bool {
+	if t.texture == nil {
+		return false
+	}
+
+	var placed []rune
+	for c, g := range t.glyphs {
+		if g.src.W > 0 {
+			placed = append(placed, c)
+		}
+	}
+
+	for size := t.size * 2; size <= atlasMaxSize; size *= 2 {
+		if t.allocate(size) != nil {
+			return false
+		}
+		if t.repack(placed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// repack renders 'runes' into the freshly allocated atlas. Runes that
+// don't fit are left undrawn and false is returned.
+func (t *DynaText) repack(runes []rune) bool {
+	for _, c := range runes {
+		t.glyphs[c].src = sdl.Rect{}
+	}
+
+	for _, c := range runes {
+		g := t.glyphs[c]
+		surface := t.rasterize(c, g)
+		if surface == nil {
+			continue
+		}
+		ok := t.upload(g, surface)
+		surface.Free()
+		if !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// kern returns the horizontal adjustment between a and b. SDL_ttf
+// doesn't expose kerning pairs, so it is derived from the width of
+// the pair versus the glyphs on their own.
+func (t *DynaText) kern(a, b rune, ga *glyph) int32 {
+	if !t.nFont.font.GetKerning() {
+		return 0
+	}
+
+	pair := [2]rune{a, b}
+	if k, ok := t.kerning[pair]; ok {
+		return k
+	}
+
+	var k int32
+	pw, _, err := t.nFont.font.SizeUTF8(string(pair[:]))
+	if err == nil {
+		bw, _, berr := t.nFont.font.SizeUTF8(string(b))
+		if berr == nil {
+			k = int32(pw) - ga.advance - int32(bw)
+		}
+	}
+
+	t.kerning[pair] = k
+
+	return k
+}
+
+// Measure returns the width and height of text in pixels.
+func (t *DynaText) Measure(text string) (w, h int32) {
+	var prev rune
+	var pg *glyph
 	for _, c := range text {
-		for _, dc := range t.txtChars {
-			if string(c) == dc.txt.text {
-				dc.txt.Bounds.X = x
-				dc.txt.Bounds.Y = t.Y
-				dc.txt.Draw()
-				x += dc.width
-			}
+		g := t.lookup(c)
+		if pg != nil {
+			w += t.kern(prev, c, pg)
 		}
+		w += g.advance
+		prev, pg = c, g
 	}
+
+	return w, t.Height
+}
+
+// Draw renders text
+func (t *DynaText) Draw(text string) {
+	t.DrawAt(t.X, t.Y, text)
 }
 
 // DrawAt renders text at the specified position
 func (t *DynaText) DrawAt(x, y int32, text string) {
+	// Make sure every rune is in the atlas before drawing; growing
+	// the atlas mid-string would invalidate earlier source rects.
+	if t.texture == nil {
+		return
+	}
+
+	for _, c := range text {
+		t.lookup(c)
+	}
+
+	t.texture.SetColorMod(t.Color.R, t.Color.G, t.Color.B)
+	t.texture.SetAlphaMod(t.Color.A)
+
+	start := x
+	var prev rune
+	var pg *glyph
 	for _, c := range text {
-		for _, dc := range t.txtChars {
-			if string(c) == dc.txt.text {
-				dc.txt.Bounds.X = x
-				dc.txt.Bounds.Y = y
-				dc.txt.Draw()
-				x += dc.width
-			}
+		g := t.glyphs[c]
+		if pg != nil {
+			x += t.kern(prev, c, pg)
+		}
+
+		if g.src.W > 0 {
+			dst := sdl.Rect{X: x, Y: y, W: g.src.W, H: g.src.H}
+			t.renderer.Copy(t.texture, &g.src, &dst)
 		}
+
+		x += g.advance
+		prev, pg = c, g
 	}
+
+	t.Width = x - start
 }
 
 // Destroy closes the Text
 func (t *DynaText) Destroy() {
-	for _, dc := range t.txtChars {
-		dc.txt.Destroy()
+	if t.texture != nil {
+		t.texture.Destroy()
+		t.texture = nil
 	}
 }
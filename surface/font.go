@@ -1,20 +1,53 @@
 package surface
 
-import "github.com/veandco/go-sdl2/ttf"
+import (
+	"fmt"
+	"os"
+
+	"github.com/veandco/go-sdl2/ttf"
+)
+
+// FontStyle is a combination of style flags applied when a font
+// is rendered.
+type FontStyle int
+
+const (
+	// FontNormal is the face as designed
+	FontNormal FontStyle = ttf.STYLE_NORMAL
+	// FontBold emboldens glyphs
+	FontBold FontStyle = ttf.STYLE_BOLD
+	// FontItalic slants glyphs
+	FontItalic FontStyle = ttf.STYLE_ITALIC
+	// FontUnderline draws a line under text
+	FontUnderline FontStyle = ttf.STYLE_UNDERLINE
+	// FontStrikethrough draws a line through text
+	FontStrikethrough FontStyle = ttf.STYLE_STRIKETHROUGH
+)
 
 // Font wraps the SDL TTF fonts.
 type Font struct {
 	font     *ttf.Font
 	fontPath string
 	size     int
+	style    FontStyle
+
+	// manager is set for fonts owned by a FontManager
+	manager *FontManager
 }
 
 // NewFont creates a Font object:
 // Ex NewFont("neuropol x rg.ttf", 16)
 func NewFont(fontPath string, size int) (*Font, error) {
+	return NewStyledFont(fontPath, size, FontNormal)
+}
+
+// NewStyledFont creates a Font object with a style:
+// Ex NewStyledFont("neuropol x rg.ttf", 16, FontBold|FontItalic)
+func NewStyledFont(fontPath string, size int, style FontStyle) (*Font, error) {
 	f := new(Font)
 	f.fontPath = fontPath
 	f.size = size
+	f.style = style
 
 	err := f.initialize()
 	if err != nil {
@@ -26,18 +59,64 @@ func NewFont(fontPath string, size int) (*Font, error) {
 
 // Initialize sets up font based on fontPath
 func (f *Font) initialize() (err error) {
-	ttf.Init()
+	if f.size <= 0 {
+		return fmt.Errorf("font: %s: size must be positive, got %d", f.fontPath, f.size)
+	}
 
-	f.font, err = ttf.OpenFont(f.fontPath, f.size)
+	info, err := os.Stat(f.fontPath)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("font: %s: file not found", f.fontPath)
+	}
+	if err != nil {
+		return fmt.Errorf("font: %v", err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("font: %s: is a directory", f.fontPath)
+	}
+
+	err = acquireTTF()
 	if err != nil {
 		return err
 	}
 
+	f.font, err = ttf.OpenFont(f.fontPath, f.size)
+	if err != nil {
+		releaseTTF()
+		return fmt.Errorf("font: %s: can't open at size %d: %v", f.fontPath, f.size, err)
+	}
+
+	f.font.SetStyle(int(f.style))
+
 	return nil
 }
 
-// Destroy closes the font
+// Path returns the file the font was loaded from.
+func (f *Font) Path() string {
+	return f.fontPath
+}
+
+// Size returns the point size the font was opened at.
+func (f *Font) Size() int {
+	return f.size
+}
+
+// Style returns the font's style flags.
+func (f *Font) Style() FontStyle {
+	return f.style
+}
+
+// Destroy closes the font. Fonts obtained from a FontManager are
+// closed by the manager and Destroy does nothing for them.
 func (f *Font) Destroy() {
+	if f.manager != nil || f.font == nil {
+		return
+	}
+
+	f.close()
+}
+
+func (f *Font) close() {
 	f.font.Close()
-	ttf.Quit()
+	f.font = nil
+	releaseTTF()
 }
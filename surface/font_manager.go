@@ -0,0 +1,101 @@
+package surface
+
+import (
+	"fmt"
+
+	"github.com/veandco/go-sdl2/ttf"
+)
+
+// ttfUsers counts open fonts and managers. SDL_ttf is initialized
+// by the first and shut down by the last, so closing one font
+// doesn't pull the library out from under the others.
+var ttfUsers int
+
+func acquireTTF() error {
+	if ttfUsers == 0 {
+		err := ttf.Init()
+		if err != nil {
+			return fmt.Errorf("font: can't initialize SDL_ttf: %v", err)
+		}
+	}
+	ttfUsers++
+	return nil
+}
+
+func releaseTTF() {
+	if ttfUsers == 0 {
+		return
+	}
+	ttfUsers--
+	if ttfUsers == 0 {
+		ttf.Quit()
+	}
+}
+
+type fontKey struct {
+	path  string
+	size  int
+	style FontStyle
+}
+
+// FontManager loads fonts once per path, size and style and shares
+// them. It keeps SDL_ttf initialized until Close.
+type FontManager struct {
+	fonts map[fontKey]*Font
+}
+
+// NewFontManager creates an empty FontManager.
+func NewFontManager() (*FontManager, error) {
+	err := acquireTTF()
+	if err != nil {
+		return nil, err
+	}
+
+	m := new(FontManager)
+	m.fonts = make(map[fontKey]*Font)
+
+	return m, nil
+}
+
+// Font returns the normal style font at path and size, loading it
+// on first use.
+func (m *FontManager) Font(path string, size int) (*Font, error) {
+	return m.StyledFont(path, size, FontNormal)
+}
+
+// StyledFont returns the font at path, size and style, loading it on
+// first use. The font stays open until the manager is closed.
+func (m *FontManager) StyledFont(path string, size int, style FontStyle) (*Font, error) {
+	if m.fonts == nil {
+		return nil, fmt.Errorf("font: %s: manager is closed", path)
+	}
+
+	key := fontKey{path: path, size: size, style: style}
+	if f, ok := m.fonts[key]; ok {
+		return f, nil
+	}
+
+	f, err := NewStyledFont(path, size, style)
+	if err != nil {
+		return nil, err
+	}
+
+	f.manager = m
+	m.fonts[key] = f
+
+	return f, nil
+}
+
+// Close closes every cached font and releases SDL_ttf.
+func (m *FontManager) Close() {
+	if m.fonts == nil {
+		return
+	}
+
+	for _, f := range m.fonts {
+		f.close()
+	}
+	m.fonts = nil
+
+	releaseTTF()
+}
@@ -0,0 +1,65 @@
+package surface
+
+import "math"
+
+// Number of frames the rolling statistics cover, about 2 seconds at 60 FPS
+const statsSamples = 120
+
+// FrameStats keeps rolling statistics of a duration, in seconds, over
+// the most recent frames.
+type FrameStats struct {
+	samples [statsSamples]float64
+	count   int
+	next    int
+}
+
+// Add records one frame's duration in seconds.
+func (s *FrameStats) Add(seconds float64) {
+	s.samples[s.next] = seconds
+	s.next = (s.next + 1) % statsSamples
+	if s.count < statsSamples {
+		s.count++
+	}
+}
+
+// Average returns the mean duration in seconds.
+func (s *FrameStats) Average() float64 {
+	if s.count == 0 {
+		return 0.0
+	}
+	sum := 0.0
+	for _, t := range s.samples[:s.count] {
+		sum += t
+	}
+	return sum / float64(s.count)
+}
+
+// Min returns the shortest duration in seconds.
+func (s *FrameStats) Min() float64 {
+	if s.count == 0 {
+		return 0.0
+	}
+	m := math.Inf(1)
+	for _, t := range s.samples[:s.count] {
+		m = math.Min(m, t)
+	}
+	return m
+}
+
+// Max returns the longest duration in seconds.
+func (s *FrameStats) Max() float64 {
+	m := 0.0
+	for _, t := range s.samples[:s.count] {
+		m = math.Max(m, t)
+	}
+	return m
+}
+
+// Rate returns the average number of frames per second.
+func (s *FrameStats) Rate() float64 {
+	avg := s.Average()
+	if avg == 0.0 {
+		return 0.0
+	}
+	return 1.0 / avg
+}
@@ -0,0 +1,214 @@
+package surface
+
+import (
+	"fmt"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Number of frames of history kept per section
+const profileHistory = 120
+
+// Section colors, reused in order
+var profileColors = [...]sdl.Color{
+	{R: 230, G: 80, B: 80, A: 255},
+	{R: 240, G: 170, B: 60, A: 255},
+	{R: 230, G: 230, B: 80, A: 255},
+	{R: 90, G: 200, B: 90, A: 255},
+	{R: 80, G: 200, B: 220, A: 255},
+	{R: 90, G: 120, B: 240, A: 255},
+	{R: 190, G: 100, B: 230, A: 255},
+	{R: 200, G: 200, B: 200, A: 255},
+}
+
+type profileSection struct {
+	name  string
+	color sdl.Color
+	// Seconds per frame, a ring buffer indexed like Profiler.frame
+	times [profileHistory]float64
+	// Seconds so far in the current frame
+	current float64
+
+	// Graph bars, reused each draw
+	rects []sdl.Rect
+	// Created by the overlay on first use
+	label *Text
+}
+
+// Profiler times named sections of each frame and keeps a history of
+// them for the overlay graph.
+type Profiler struct {
+	// Visible shows the overlay
+	Visible bool
+
+	sections []*profileSection
+	index    map[string]*profileSection
+
+	active *profileSection
+	start  uint64
+
+	// Slot the current frame is recorded into and completed frame count
+	frame  int
+	frames int
+}
+
+// NewProfiler creates an empty, hidden profiler.
+func NewProfiler() *Profiler {
+	p := new(Profiler)
+	p.index = map[string]*profileSection{}
+	return p
+}
+
+// Begin ends the running section, if any, and starts timing 'name'.
+// A section may be begun several times a frame and its times add up.
+func (p *Profiler) Begin(name string) {
+	now := sdl.GetPerformanceCounter()
+	p.stop(now)
+
+	s, ok := p.index[name]
+	if !ok {
+		s = &profileSection{name: name, color: profileColors[len(p.sections)%len(profileColors)]}
+		p.sections = append(p.sections, s)
+		p.index[name] = s
+	}
+	p.active = s
+	p.start = now
+}
+
+// End ends the running section.
+func (p *Profiler) End() {
+	p.stop(sdl.GetPerformanceCounter())
+}
+
+func (p *Profiler) stop(now uint64) {
+	if p.active == nil {
+		return
+	}
+	p.active.current += float64(now-p.start) / float64(sdl.GetPerformanceFrequency())
+	p.active = nil
+}
+
+// EndFrame records the frame's section times into the history.
+func (p *Profiler) EndFrame() {
+	p.End()
+	for _, s := range p.sections {
+		s.times[p.frame] = s.current
+		s.current = 0.0
+	}
+	p.frame = (p.frame + 1) % profileHistory
+	if p.frames < profileHistory {
+		p.frames++
+	}
+}
+
+// Stats returns the minimum, average and maximum seconds per frame of a
+// section over the history.
+func (p *Profiler) Stats(name string) (min, avg, max float64) {
+	s, ok := p.index[name]
+	if !ok || p.frames == 0 {
+		return 0.0, 0.0, 0.0
+	}
+	return s.stats(p.frames)
+}
+
+func (s *profileSection) stats(frames int) (min, avg, max float64) {
+	min = s.times[0]
+	for _, t := range s.times[:frames] {
+		if t < min {
+			min = t
+		}
+		if t > max {
+			max = t
+		}
+		avg += t
+	}
+	avg /= float64(frames)
+	return min, avg, max
+}
+
+// Draw renders the overlay with its bottom left corner at (x, bottom).
+// The graph is stacked bars per frame, oldest on the left, scaled so the
+// midline is 'budget' seconds. A legend of min/avg/max milliseconds per
+// section sits above it.
+func (p *Profiler) Draw(renderer *sdl.Renderer, font *Font, dynaTxt *DynaText, x, bottom int32, budget float64) {
+	if !p.Visible || p.frames == 0 {
+		return
+	}
+
+	const (
+		barWidth    = 2
+		graphHeight = 80
+		lineHeight  = 16
+		valuesX     = 90
+	)
+	graphWidth := int32(profileHistory * barWidth)
+	legendHeight := int32(len(p.sections)+1) * lineHeight
+	top := bottom - graphHeight - legendHeight - 4
+
+	renderer.SetDrawBlendMode(sdl.BLENDMODE_BLEND)
+	renderer.SetDrawColor(0, 0, 0, 160)
+	renderer.FillRect(&sdl.Rect{X: x - 4, Y: top - 4, W: graphWidth + 8, H: bottom - top + 8})
+
+	// Stacked bars, oldest frame first
+	scale := graphHeight / (2.0 * budget)
+	for _, s := range p.sections {
+		s.rects = s.rects[:0]
+	}
+	for i := 0; i < p.frames; i++ {
+		slot := (p.frame - p.frames + i + profileHistory) % profileHistory
+		y := bottom
+		for _, s := range p.sections {
+			h := int32(s.times[slot]*scale + 0.5)
+			if y-h < bottom-graphHeight {
+				h = y - (bottom - graphHeight)
+			}
+			if h <= 0 {
+				continue
+			}
+			y -= h
+			s.rects = append(s.rects, sdl.Rect{X: x + int32(i*barWidth), Y: y, W: barWidth, H: h})
+		}
+	}
+	for _, s := range p.sections {
+		if len(s.rects) > 0 {
+			renderer.SetDrawColor(s.color.R, s.color.G, s.color.B, s.color.A)
+			renderer.FillRects(s.rects)
+		}
+	}
+
+	// The budget line
+	renderer.SetDrawColor(255, 255, 255, 200)
+	renderer.DrawLine(x, bottom-graphHeight/2, x+graphWidth, bottom-graphHeight/2)
+
+	// Legend
+	y := top
+	dynaTxt.DrawAt(x+valuesX, y, "min   avg   max ms")
+	for _, s := range p.sections {
+		y += lineHeight
+		renderer.SetDrawColor(s.color.R, s.color.G, s.color.B, s.color.A)
+		renderer.FillRect(&sdl.Rect{X: x, Y: y + 4, W: 8, H: 8})
+
+		if s.label == nil {
+			s.label = NewText(font, renderer)
+			if s.label.SetText(s.name, s.color) != nil {
+				s.label = nil
+			}
+		}
+		if s.label != nil {
+			s.label.DrawAt(x+12, y)
+		}
+
+		min, avg, max := s.stats(p.frames)
+		dynaTxt.DrawAt(x+valuesX, y, fmt.Sprintf("%5.2f %5.2f %5.2f", min*1000.0, avg*1000.0, max*1000.0))
+	}
+}
+
+// Destroy releases the legend's textures.
+func (p *Profiler) Destroy() {
+	for _, s := range p.sections {
+		if s.label != nil {
+			s.label.Destroy()
+			s.label = nil
+		}
+	}
+}
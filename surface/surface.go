@@ -1,12 +1,154 @@
 package surface
 
+import (
+	"SoftRenderer/imaging"
+	"errors"
+	"flag"
+	"fmt"
+)
+
+// ResizePolicy decides what happens to the raster when the window's size
+// changes.
+type ResizePolicy int
+
 const (
-	// SurfaceScale scales the view
-	SurfaceScale = 2
-	width        = 320 * SurfaceScale
-	height       = 240 * SurfaceScale
-	windowPosX   = 1024
-	windowPosY   = 768
-	fps          = 60.0
-	framePeriod  = 1.0 / fps * 1000.0
+	// ResizeLetterbox keeps the raster's resolution and scales it to fit
+	// the window, adding bars to preserve the aspect ratio.
+	ResizeLetterbox ResizePolicy = iota
+	// ResizeReallocate resizes the raster and texture to the window's
+	// size divided by Scale.
+	ResizeReallocate
 )
+
+var resizePolicyNames = [...]string{"letterbox", "reallocate"}
+
+func (p ResizePolicy) String() string {
+	if p < 0 || int(p) >= len(resizePolicyNames) {
+		return fmt.Sprintf("ResizePolicy(%d)", int(p))
+	}
+	return resizePolicyNames[p]
+}
+
+// Set parses a policy name, allowing a policy to be used as a flag.Value.
+func (p *ResizePolicy) Set(s string) error {
+	for i, n := range resizePolicyNames {
+		if n == s {
+			*p = ResizePolicy(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown resize policy %q, want letterbox or reallocate", s)
+}
+
+// Config describes the window and frame loop of a surface.
+type Config struct {
+	// Title is the window's caption
+	Title string
+	// Width and Height are the raster resolution in pixels
+	Width  int
+	Height int
+	// Scale is the integer number of window pixels per raster pixel
+	Scale int
+	// PosX and PosY place the window. sdl.WINDOWPOS_CENTERED and
+	// sdl.WINDOWPOS_UNDEFINED are allowed.
+	PosX int
+	PosY int
+
+	// FPS is the target frame rate when neither Uncapped nor VSync is set
+	FPS float64
+	// Uncapped renders frames as fast as possible
+	Uncapped bool
+	// VSync paces frames by the display's refresh rate
+	VSync bool
+	// UpdateRate is how many fixed steps per second the application is
+	// updated by, independent of the frame rate
+	UpdateRate float64
+
+	// Resizable lets the user resize the window
+	Resizable bool
+	// Fullscreen starts the window fullscreen at the desktop resolution.
+	// F11 toggles it.
+	Fullscreen bool
+	// ResizePolicy applies whenever the window's size changes, including
+	// entering and leaving fullscreen
+	ResizePolicy ResizePolicy
+
+	// ScreenshotDir is where F12 and Screenshot save images
+	ScreenshotDir string
+	// ScreenshotFormat is the image file extension: png, ppm, pam,
+	// tga or bmp
+	ScreenshotFormat string
+}
+
+// DefaultConfig returns a 640x480 window running at 60 FPS.
+func DefaultConfig() Config {
+	return Config{
+		Title:  "Soft renderer",
+		Width:  640,
+		Height: 480,
+		Scale:  1,
+		PosX:   1024,
+		PosY:   768,
+		FPS:    60.0,
+
+		UpdateRate: 60.0,
+
+		ScreenshotDir:    ".",
+		ScreenshotFormat: "png",
+	}
+}
+
+// RegisterFlags adds command-line flags for each field to 'fs' using the
+// config's current values as defaults. Call fs.Parse afterwards.
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Title, "title", c.Title, "window title")
+	fs.IntVar(&c.Width, "width", c.Width, "raster width in pixels")
+	fs.IntVar(&c.Height, "height", c.Height, "raster height in pixels")
+	fs.IntVar(&c.Scale, "scale", c.Scale, "window pixels per raster pixel")
+	fs.IntVar(&c.PosX, "x", c.PosX, "window x position")
+	fs.IntVar(&c.PosY, "y", c.PosY, "window y position")
+	fs.Float64Var(&c.FPS, "fps", c.FPS, "target frames per second")
+	fs.BoolVar(&c.Uncapped, "uncapped", c.Uncapped, "don't limit the frame rate")
+	fs.BoolVar(&c.VSync, "vsync", c.VSync, "synchronize frames with the display")
+	fs.Float64Var(&c.UpdateRate, "ups", c.UpdateRate, "fixed updates per second")
+	fs.BoolVar(&c.Resizable, "resizable", c.Resizable, "allow the window to be resized")
+	fs.BoolVar(&c.Fullscreen, "fullscreen", c.Fullscreen, "start fullscreen")
+	fs.Var(&c.ResizePolicy, "resize", "on resize either letterbox or reallocate the raster")
+	fs.StringVar(&c.ScreenshotDir, "shots", c.ScreenshotDir, "directory screenshots are saved to")
+	fs.StringVar(&c.ScreenshotFormat, "shotfmt", c.ScreenshotFormat, "screenshot format: png, ppm, pam, tga or bmp")
+}
+
+// Validate reports the first invalid setting.
+func (c *Config) Validate() error {
+	if c.Width <= 0 || c.Height <= 0 {
+		return fmt.Errorf("surface: invalid resolution %dx%d", c.Width, c.Height)
+	}
+	if c.Scale < 1 {
+		return fmt.Errorf("surface: scale must be at least 1, got %d", c.Scale)
+	}
+	if c.Uncapped && c.VSync {
+		return errors.New("surface: uncapped and vsync can't both be set")
+	}
+	if !c.Uncapped && !c.VSync && c.FPS <= 0 {
+		return fmt.Errorf("surface: fps must be positive, got %g", c.FPS)
+	}
+	if c.UpdateRate <= 0 {
+		return fmt.Errorf("surface: update rate must be positive, got %g", c.UpdateRate)
+	}
+	if c.ResizePolicy != ResizeLetterbox && c.ResizePolicy != ResizeReallocate {
+		return fmt.Errorf("surface: invalid resize policy %v", c.ResizePolicy)
+	}
+	if _, err := imaging.EncoderFor("." + c.ScreenshotFormat); err != nil {
+		return fmt.Errorf("surface: invalid screenshot format %q, want png, ppm, pam, tga or bmp", c.ScreenshotFormat)
+	}
+	return nil
+}
+
+// framePeriod is the target time between frames in seconds, or 0 if
+// frames aren't paced by sleeping.
+func (c *Config) framePeriod() float64 {
+	if c.Uncapped || c.VSync {
+		return 0.0
+	}
+	return 1.0 / c.FPS
+}
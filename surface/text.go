@@ -1,18 +1,73 @@
 package surface
 
 import (
+	"strings"
+
 	"github.com/veandco/go-sdl2/sdl"
 )
 
-// Text represents a text texture for rendering.
+// TextQuality selects how SDL_ttf rasterizes text.
+type TextQuality int
+
+const (
+	// QualitySolid is fast, aliased text with a transparent background
+	QualitySolid TextQuality = iota
+	// QualityShaded is anti-aliased text on an opaque Background
+	QualityShaded
+	// QualityBlended is anti-aliased text with a transparent background
+	QualityBlended
+	// QualityOutlined is blended text drawn over an OutlineColor border
+	QualityOutlined
+)
+
+// TextAlign positions lines horizontally within the text block.
+type TextAlign int
+
+const (
+	// AlignLeft starts lines at the block's left edge
+	AlignLeft TextAlign = iota
+	// AlignCenter centers lines in the block
+	AlignCenter
+	// AlignRight ends lines at the block's right edge
+	AlignRight
+)
+
+// textLine is one laid out line. outline is only set for
+// QualityOutlined and is drawn beneath texture, which is inset by the
+// outline thickness it was rendered with.
+type textLine struct {
+	texture *sdl.Texture
+	outline *sdl.Texture
+	inset   int32
+	bounds  sdl.Rect
+}
+
+// Text represents a text texture for rendering. Lines are
+// separated by '\n' and, when WrapWidth is set, wrapped at word
+// boundaries. Layout fields take effect on the next SetText.
 type Text struct {
 	nFont    *Font
 	renderer *sdl.Renderer
 
-	texture *sdl.Texture
-	color   sdl.Color
-	text    string
-	Bounds  sdl.Rect
+	lines []textLine
+	color sdl.Color
+	text  string
+
+	Quality TextQuality
+	Align   TextAlign
+	// WrapWidth wraps lines wider than this many pixels. Zero disables
+	// wrapping. When set it is also the width lines are aligned in.
+	WrapWidth int32
+	// LineSpacing is added to the font's line skip.
+	LineSpacing int32
+	// Background fills behind QualityShaded text
+	Background sdl.Color
+	// OutlineColor and Outline are the border color and thickness in
+	// pixels for QualityOutlined.
+	OutlineColor sdl.Color
+	Outline      int
+
+	Bounds sdl.Rect
 }
 
 // NewText creates a Text object.
@@ -27,64 +82,208 @@ func NewText(font *Font, renderer *sdl.Renderer) *Text {
 
 // Initialize sets up Text based on TextPath
 func (t *Text) initialize() error {
+	t.Quality = QualitySolid
+	t.Align = AlignLeft
+	t.Background = sdl.Color{R: 0, G: 0, B: 0, A: 255}
+	t.OutlineColor = sdl.Color{R: 0, G: 0, B: 0, A: 255}
+	t.Outline = 1
 	return nil
 }
 
-// SetText builds an SDL texture. Be sure to call Destroy before
-// program exit.
+// SetText lays out text and builds SDL textures for each line.
+// Be sure to call Destroy before program exit.
 func (t *Text) SetText(text string, color sdl.Color) (err error) {
 	t.text = text
 	t.color = color
 
 	t.Destroy()
 
+	var y, width int32
+	skip := int32(t.nFont.font.LineSkip()) + t.LineSpacing
+
+	for _, s := range t.wrap(text) {
+		line := textLine{bounds: sdl.Rect{Y: y}}
+
+		if s != "" {
+			line, err = t.renderLine(s)
+			if err != nil {
+				t.Destroy()
+				return err
+			}
+			line.bounds.Y = y
+		}
+
+		if line.bounds.W > width {
+			width = line.bounds.W
+		}
+
+		t.lines = append(t.lines, line)
+		y += skip
+	}
+
+	if t.WrapWidth > 0 {
+		width = t.WrapWidth
+	}
+
+	for i := range t.lines {
+		l := &t.lines[i]
+		switch t.Align {
+		case AlignCenter:
+			l.bounds.X = (width - l.bounds.W) / 2
+		case AlignRight:
+			l.bounds.X = width - l.bounds.W
+		}
+	}
+
+	// The last line only needs the font height, not a full skip.
+	height := y - skip + int32(t.nFont.font.Height())
+	if len(t.lines) == 0 {
+		height = 0
+	}
+
+	t.Bounds = sdl.Rect{X: t.Bounds.X, Y: t.Bounds.Y, W: width, H: height}
+
+	return nil
+}
+
+// wrap splits text into lines at newlines and, if WrapWidth is set,
+// between words. A word wider than WrapWidth gets a line of its own.
+func (t *Text) wrap(text string) []string {
+	paragraphs := strings.Split(text, "\n")
+	if t.WrapWidth <= 0 {
+		return paragraphs
+	}
+
+	// An outline widens the line on both sides
+	pad := int32(0)
+	if t.Quality == QualityOutlined && t.Outline > 0 {
+		pad = int32(t.Outline) * 2
+	}
+
+	lines := []string{}
+	for _, p := range paragraphs {
+		words := strings.Fields(p)
+		if len(words) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+
+		line := words[0]
+		for _, w := range words[1:] {
+			candidate := line + " " + w
+			cw, _, err := t.nFont.font.SizeUTF8(candidate)
+			if err == nil && int32(cw)+pad <= t.WrapWidth {
+				line = candidate
+				continue
+			}
+			lines = append(lines, line)
+			line = w
+		}
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// renderLine rasterizes a single line in the current Quality.
+func (t *Text) renderLine(s string) (line textLine, err error) {
+	font := t.nFont.font
+
 	var surface *sdl.Surface
 
 	// First we draw an image to a surface
-	surface, err = t.nFont.font.RenderUTF8Solid(text, t.color)
+	switch t.Quality {
+	case QualityShaded:
+		surface, err = font.RenderUTF8Shaded(s, t.color, t.Background)
+	case QualityBlended, QualityOutlined:
+		surface, err = font.RenderUTF8Blended(s, t.color)
+	default:
+		surface, err = font.RenderUTF8Solid(s, t.color)
+	}
 	if err != nil {
-		return err
+		return textLine{}, err
 	}
 
-	// Now generate a texture for rendering, using the surface.
-	t.texture, err = t.renderer.CreateTextureFromSurface(surface)
+	// Now generate a texture for rendering, using the surface. The
+	// surface's size must be read before it is freed.
+	line.texture, err = t.renderer.CreateTextureFromSurface(surface)
+	line.bounds = sdl.Rect{W: surface.W, H: surface.H}
+	surface.Free()
 	if err != nil {
-		t.Destroy()
-		return err
+		return textLine{}, err
 	}
 
-	_, _, width, height, qerr := t.texture.Query()
-	if qerr != nil {
-		t.Destroy()
-		return qerr
+	if t.Quality != QualityOutlined || t.Outline <= 0 {
+		return line, nil
 	}
 
-	t.Bounds = sdl.Rect{X: 0, Y: 0, W: width, H: height}
+	// The outline is the same text rendered with a stroked font; it
+	// is Outline pixels larger on every side.
+	prev := font.GetOutline()
+	font.SetOutline(t.Outline)
+	surface, err = font.RenderUTF8Blended(s, t.OutlineColor)
+	font.SetOutline(prev)
+	if err != nil {
+		line.texture.Destroy()
+		return textLine{}, err
+	}
 
-	// We don't need the surface any longer now that the texture
-	// is created.
+	line.outline, err = t.renderer.CreateTextureFromSurface(surface)
 	surface.Free()
+	if err != nil {
+		line.texture.Destroy()
+		return textLine{}, err
+	}
 
-	return nil
+	line.inset = int32(t.Outline)
+	line.bounds.W += line.inset * 2
+	line.bounds.H += line.inset * 2
+
+	return line, nil
 }
 
 // Draw renders text
 func (t *Text) Draw() {
-	t.renderer.Copy(t.texture, nil, &t.Bounds)
+	for _, l := range t.lines {
+		if l.texture == nil {
+			continue
+		}
+
+		dst := l.bounds
+		dst.X += t.Bounds.X
+		dst.Y += t.Bounds.Y
+
+		if l.outline != nil {
+			t.renderer.Copy(l.outline, nil, &dst)
+			o := l.inset
+			dst = sdl.Rect{X: dst.X + o, Y: dst.Y + o, W: dst.W - 2*o, H: dst.H - 2*o}
+		}
+
+		t.renderer.Copy(l.texture, nil, &dst)
+	}
 }
 
 // DrawAt renders text
 func (t *Text) DrawAt(x, y int32) {
 	t.Bounds.X = x
 	t.Bounds.Y = y
-	t.renderer.Copy(t.texture, nil, &t.Bounds)
+	t.Draw()
 }
 
 // Destroy closes the Text
 func (t *Text) Destroy() error {
-	if t.texture != nil {
-		return t.texture.Destroy()
+	var err error
+	for _, l := range t.lines {
+		if l.outline != nil {
+			l.outline.Destroy()
+		}
+		if l.texture != nil {
+			if derr := l.texture.Destroy(); derr != nil {
+				err = derr
+			}
+		}
 	}
+	t.lines = nil
 
-	return nil
+	return err
 }
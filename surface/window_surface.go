@@ -2,17 +2,25 @@ package surface
 
 import (
 	"SoftRenderer/api"
-	graphics "SoftRenderer/graphcs"
+	"SoftRenderer/imaging"
 	"SoftRenderer/renderer"
+	"errors"
 	"fmt"
-	"image/color"
+	"image"
 	"log"
-	"math"
-	"time"
+	"sync"
 
 	"github.com/veandco/go-sdl2/sdl"
 )
 
+const (
+	// Longest frame time fed to the update accumulator, in seconds
+	maxFrameTime = 0.25
+	// The final part of a frame wait, in seconds, that is spun rather
+	// than slept
+	spinTime = 0.002
+)
+
 // WindowSurface is the GUI and shows the plots and graphs.
 // It receives commands for graphing and viewing various graphs.
 type WindowSurface struct {
@@ -23,63 +31,87 @@ type WindowSurface struct {
 
 	rasterBuffer api.IRasterBuffer
 
+	config Config
+
+	frameStats FrameStats
+	loopStats  FrameStats
+	profiler   *Profiler
+
+	fullscreen bool
+	// Set when the window's size changed. Handled at the start of the
+	// next frame rather than inside the event filter.
+	resized bool
+
 	// mouse
 	mx int32
 	my int32
 
-	// Debug/testing stuff
-	mod  int
-	dir  int
-	dir2 int
-	dir3 int
-	xx   int
-	xx2  int
-	xx3  int
+	app api.IApplication
+
+	capture  capture
+	recorder *imaging.GIFRecorder
+	writes   sync.WaitGroup
+
+	// Depth visualization and the image shown this frame
+	showDepth   bool
+	depthView   *imaging.DepthView
+	depthPixels *image.RGBA
+	frame       *image.RGBA
 
 	running bool
-	animate bool
-	step    bool
 
 	opened bool
 
-	nFont        *Font
-	txtSimStatus *Text
-	txtFPSLabel  *Text
-	txtLoopLabel *Text
-	txtMousePos  *Text
-	dynaTxt      *DynaText
+	fonts         *FontManager
+	nFont         *Font
+	txtSimStatus  *Text
+	txtFPSLabel   *Text
+	txtLoopLabel  *Text
+	txtMousePos   *Text
+	txtDepthLabel *Text
+	dynaTxt       *DynaText
 }
 
-// NewSurfaceBuffer creates a new viewer and initializes it.
+// NewSurfaceBuffer creates a new viewer using DefaultConfig.
 func NewSurfaceBuffer() api.ISurface {
+	return NewSurfaceBufferWithConfig(DefaultConfig())
+}
+
+// NewSurfaceBufferWithConfig creates a new viewer. The config is
+// validated when the viewer is opened.
+func NewSurfaceBufferWithConfig(config Config) api.ISurface {
 	o := new(WindowSurface)
 	o.opened = false
-	o.animate = true
-	o.step = false
-	o.mod = 200
-	o.xx = 75 // -41 //75 // x2
-	o.dir = 1
-	o.xx2 = 0 //-29 //0 // x1
-	o.dir2 = 1
-	o.xx3 = 100 //28 //100 // y1
-	o.dir3 = 1
-	//x1  -29 y1  8 x2  -41
+	o.config = config
+	o.profiler = NewProfiler()
+	o.depthView = imaging.NewDepthView(0.0)
 	return o
 }
 
-func (ws *WindowSurface) initialize() {
+func (ws *WindowSurface) initialize() error {
 	var err error
+	cfg := &ws.config
 
 	err = sdl.Init(sdl.INIT_TIMER | sdl.INIT_VIDEO | sdl.INIT_EVENTS)
 	if err != nil {
-		panic(err)
+		return err
 	}
 
-	ws.window, err = sdl.CreateWindow("Soft renderer", windowPosX, windowPosY,
-		width, height, sdl.WINDOW_SHOWN)
+	var windowFlags uint32 = sdl.WINDOW_SHOWN
+	if cfg.Resizable {
+		windowFlags |= sdl.WINDOW_RESIZABLE
+	}
+	if cfg.Fullscreen {
+		windowFlags |= sdl.WINDOW_FULLSCREEN_DESKTOP
+	}
+	ws.fullscreen = cfg.Fullscreen
+
+	ws.window, err = sdl.CreateWindow(cfg.Title, int32(cfg.PosX), int32(cfg.PosY),
+		int32(cfg.Width*cfg.Scale), int32(cfg.Height*cfg.Scale), windowFlags)
 
 	if err != nil {
-		panic(err)
+		sdl.Quit()
+		return err
 	}
 
 	// Using GetSurface requires using window.UpdateSurface() rather than renderer.Present.
@@ -89,20 +121,103 @@ func (ws *WindowSurface) initialize() {
 	// }
 	// ws.renderer, err = sdl.CreateSoftwareRenderer(ws.surface)
 	// OR create renderer manually
-	ws.renderer, err = sdl.CreateRenderer(ws.window, -1, sdl.RENDERER_ACCELERATED)
+	var flags uint32 = sdl.RENDERER_ACCELERATED
+	if cfg.VSync {
+		flags |= sdl.RENDERER_PRESENTVSYNC
+	}
+	ws.renderer, err = sdl.CreateRenderer(ws.window, -1, flags)
 	if err != nil {
-		panic(err)
+		ws.window.Destroy()
+		sdl.Quit()
+		return err
 	}
 
-	ws.texture, err = ws.renderer.CreateTexture(sdl.PIXELFORMAT_ABGR8888, sdl.TEXTUREACCESS_STREAMING, width, height)
+	// Everything is drawn at the raster's resolution and scaled up to the
+	// window. Mouse coordinates are scaled back down by SDL.
+	err = ws.renderer.SetLogicalSize(int32(cfg.Width), int32(cfg.Height))
+	if err == nil {
+		ws.texture, err = ws.renderer.CreateTexture(sdl.PIXELFORMAT_ABGR8888, sdl.TEXTUREACCESS_STREAMING,
+			int32(cfg.Width), int32(cfg.Height))
+	}
 	if err != nil {
-		panic(err)
+		ws.renderer.Destroy()
+		ws.window.Destroy()
+		sdl.Quit()
+		return err
 	}
 
 	// ws.renderer.SetDrawBlendMode(sdl.BLENDMODE_BLEND)
 
-	ws.rasterBuffer = renderer.NewRasterBuffer(width, height)
+	ws.rasterBuffer = renderer.NewRasterBuffer(cfg.Width, cfg.Height)
 	// ws.rasterBuffer.EnableAlphaBlending(true)
+
+	// Starting fullscreen may already be a different size.
+	ws.resized = cfg.Fullscreen
+	return nil
+}
+
+// ToggleFullscreen switches between a window and fullscreen at the
+// desktop's resolution.
+func (ws *WindowSurface) ToggleFullscreen() error {
+	var flags uint32
+	if !ws.fullscreen {
+		flags = sdl.WINDOW_FULLSCREEN_DESKTOP
+	}
+	err := ws.window.SetFullscreen(flags)
+	if err != nil {
+		return err
+	}
+	ws.fullscreen = !ws.fullscreen
+	return nil
+}
+
+// resize applies the resize policy after the window's size changed.
+func (ws *WindowSurface) resize() error {
+	ws.resized = false
+	if ws.config.ResizePolicy == ResizeLetterbox {
+		// The renderer's logical size already scales and letterboxes.
+		return nil
+	}
+
+	w, h, err := ws.renderer.GetOutputSize()
+	if err != nil {
+		return err
+	}
+	rw := int(w) / ws.config.Scale
+	rh := int(h) / ws.config.Scale
+	if rw < 1 {
+		rw = 1
+	}
+	if rh < 1 {
+		rh = 1
+	}
+
+	bounds := ws.rasterBuffer.Pixels().Bounds()
+	if rw == bounds.Dx() && rh == bounds.Dy() {
+		return nil
+	}
+
+	err = ws.texture.Destroy()
+	if err != nil {
+		return err
+	}
+	ws.texture, err = ws.renderer.CreateTexture(sdl.PIXELFORMAT_ABGR8888, sdl.TEXTUREACCESS_STREAMING,
+		int32(rw), int32(rh))
+	if err != nil {
+		return err
+	}
+	err = ws.renderer.SetLogicalSize(int32(rw), int32(rh))
+	if err != nil {
+		return err
+	}
+
+	// Buffer state, for example blending, starts over with the new buffer.
+	ws.rasterBuffer = renderer.NewRasterBuffer(rw, rh)
+
+	if handler, ok := ws.app.(api.IResizeHandler); ok {
+		handler.Resize(rw, rh)
+	}
+	return nil
 }
 
 // Configure view with draw objects
@@ -135,61 +250,118 @@ func (ws *WindowSurface) Configure() {
 		panic(err)
 	}
 
+	ws.txtDepthLabel = NewText(ws.nFont, ws.renderer)
+	err = ws.txtDepthLabel.SetText("Depth: ", sdl.Color{R: 200, G: 200, B: 200, A: 255})
+	if err != nil {
+		ws.Close()
+		panic(err)
+	}
+
 	ws.dynaTxt = NewDynaText(ws.nFont, ws.renderer, sdl.Color{R: 255, G: 255, B: 255, A: 255})
 }
 
-// Open shows the viewer and begins event polling
+// Open validates the config and shows the viewer
 // (host deuron.IHost)
-func (ws *WindowSurface) Open() {
-	ws.initialize()
+func (ws *WindowSurface) Open() error {
+	err := ws.config.Validate()
+	if err != nil {
+		return err
+	}
+
+	err = ws.initialize()
+	if err != nil {
+		return err
+	}
 
 	ws.opened = true
+	return nil
 }
 
 // SetFont sets the font based on path and size.
 func (ws *WindowSurface) SetFont(fontPath string, size int) error {
-	var err error
-	ws.nFont, err = NewFont(fontPath, size)
+	fonts, err := ws.Fonts()
+	if err != nil {
+		return err
+	}
+
+	ws.nFont, err = fonts.Font(fontPath, size)
 	return err
 }
 
+// Fonts returns the surface's font manager, creating it on first
+// use. Fonts from it are closed along with the surface.
+func (ws *WindowSurface) Fonts() (*FontManager, error) {
+	if ws.fonts == nil {
+		fonts, err := NewFontManager()
+		if err != nil {
+			return nil, err
+		}
+		ws.fonts = fonts
+	}
+
+	return ws.fonts, nil
+}
+
 // filterEvent returns false if it handled the event. Returning false
 // prevents the event from being added to the queue.
 func (ws *WindowSurface) filterEvent(e sdl.Event, userdata interface{}) bool {
-	switch t := e.(type) {
-	case *sdl.QuitEvent:
+	if t, ok := e.(*sdl.MouseMotionEvent); ok {
+		ws.mx = t.X
+		ws.my = t.Y
+	}
+
+	if _, ok := e.(*sdl.QuitEvent); ok {
 		ws.running = false
 		return false // We handled it. Don't allow it to be added to the queue.
+	}
+
+	if t, ok := e.(*sdl.WindowEvent); ok && t.Event == sdl.WINDOWEVENT_SIZE_CHANGED {
+		ws.resized = true
+	}
+
+	if ws.app != nil && ws.app.HandleEvent(e) {
+		return false
+	}
+
+	switch t := e.(type) {
 	case *sdl.MouseMotionEvent:
-		ws.mx = t.X
-		ws.my = t.Y
 		// fmt.Printf("[%d ms] MouseMotion\ttype:%d\tid:%d\tx:%d\ty:%d\txrel:%d\tyrel:%d\n",
 		// 	t.Timestamp, t.Type, t.Which, t.X, t.Y, t.XRel, t.YRel)
 		return false // We handled it. Don't allow it to be added to the queue.
-		// case *sdl.MouseButtonEvent:
-		// 	fmt.Printf("[%d ms] MouseButton\ttype:%d\tid:%d\tx:%d\ty:%d\tbutton:%d\tstate:%d\n",
-		// 		t.Timestamp, t.Type, t.Which, t.X, t.Y, t.Button, t.State)
-		// case *sdl.MouseWheelEvent:
-		// 	fmt.Printf("[%d ms] MouseWheel\ttype:%d\tid:%d\tx:%d\ty:%d\n",
-		// 		t.Timestamp, t.Type, t.Which, t.X, t.Y)
 	case *sdl.KeyboardEvent:
 		if t.State == sdl.PRESSED {
 			switch t.Keysym.Scancode {
 			case sdl.SCANCODE_ESCAPE:
 				ws.running = false
-			case sdl.SCANCODE_A:
-				ws.animate = !ws.animate
-			case sdl.SCANCODE_S:
-				ws.step = true
-				// case 'o':
-				// 	// Stop sim
-				// 	// simStatus = "Stopping"
-				// case 'p':
-				// 	// Pause sim
-				// 	// simStatus = "Pausing"
-				// case 'e':
-				// Step sim
-				// simStatus = "Stepping"
+			case sdl.SCANCODE_F3:
+				ws.profiler.Visible = !ws.profiler.Visible
+			case sdl.SCANCODE_F4:
+				if t.Keysym.Mod&sdl.KMOD_SHIFT != 0 {
+					ws.depthView.Ramp = (ws.depthView.Ramp + 1) % 2
+				} else if t.Keysym.Mod&sdl.KMOD_CTRL != 0 {
+					ws.depthView.Linearize = !ws.depthView.Linearize
+				} else {
+					ws.ShowDepth(!ws.showDepth)
+				}
+			case sdl.SCANCODE_F11:
+				err := ws.ToggleFullscreen()
+				if err != nil {
+					log.Println(err)
+				}
+			case sdl.SCANCODE_F10:
+				if ws.Recording() {
+					ws.StopRecording()
+				} else {
+					ws.StartRecording()
+				}
+			case sdl.SCANCODE_F12:
+				// Events are handled before the raster is cleared, so
+				// the depth buffer still holds the last frame.
+				if t.Keysym.Mod&sdl.KMOD_CTRL != 0 {
+					ws.saveDepthShot()
+				} else {
+					ws.Screenshot(t.Keysym.Mod&sdl.KMOD_SHIFT == 0)
+				}
 			}
 		}
 		// fmt.Printf("[%d ms] Keyboard\ttype:%d\tsym:%c\tmodifiers:%d\tstate:%d\trepeat:%d\n",
@@ -200,49 +372,94 @@ func (ws *WindowSurface) filterEvent(e sdl.Event, userdata interface{}) bool {
 	return true
 }
 
+// SetApplication sets the application that Run drives. It must be set
+// before Run.
+func (ws *WindowSurface) SetApplication(app api.IApplication) {
+	ws.app = app
+}
+
+// Profiler returns the frame profiler. Applications can split their time
+// into sections of their own, for example, geometry and raster.
+func (ws *WindowSurface) Profiler() api.IProfiler {
+	return ws.profiler
+}
+
+// RasterBuffer returns the buffer the application renders into.
+func (ws *WindowSurface) RasterBuffer() api.IRasterBuffer {
+	return ws.rasterBuffer
+}
+
 // Run starts the polling event loop. This must run on
-// the main thread.
-func (ws *WindowSurface) Run() {
-	// log.Println("Starting viewer polling")
-	ws.running = true
-	// var simStatus = ""
-	var frameStart time.Time
-	var elapsedTime float64
-	var loopTime float64
+// the main thread. The application is initialized before the first frame
+// and shut down after the last.
+func (ws *WindowSurface) Run() error {
+	if ws.app == nil {
+		return errors.New("surface: Run needs an application, see SetApplication")
+	}
 
-	sleepDelay := 0.0
+	err := ws.app.Init(ws)
+	if err != nil {
+		return err
+	}
+	defer ws.app.Shutdown()
 
-	// Get a reference to SDL's internal keyboard state. It is updated
-	// during sdl.PollEvent()
-	keyState := sdl.GetKeyboardState()
+	// log.Println("Starting viewer polling")
+	ws.running = true
 
-	rasterizer := renderer.NewBresenHamRasterizer()
+	step := 1.0 / ws.config.UpdateRate
+	framePeriod := ws.config.framePeriod()
+	accumulator := 0.0
 
 	sdl.SetEventFilterFunc(ws.filterEvent, nil)
 
+	lastFrameStart := sdl.GetPerformanceCounter()
+
 	for ws.running {
-		frameStart = time.Now()
+		frameStart := sdl.GetPerformanceCounter()
+		frameTime := ws.seconds(frameStart - lastFrameStart)
+		lastFrameStart = frameStart
+		ws.frameStats.Add(frameTime)
 
+		ws.profiler.Begin("events")
 		sdl.PumpEvents()
 
-		if keyState[sdl.SCANCODE_Z] != 0 {
-			ws.mod--
+		if ws.resized {
+			err = ws.resize()
+			if err != nil {
+				return err
+			}
 		}
-		if keyState[sdl.SCANCODE_X] != 0 {
-			ws.mod++
+
+		// Update in fixed steps so the application runs at the same
+		// speed at any frame rate. After a long stall, for example a
+		// breakpoint, the skipped time is dropped instead of caught up.
+		if frameTime > maxFrameTime {
+			frameTime = maxFrameTime
+		}
+		accumulator += frameTime
+		ws.profiler.Begin("update")
+		for accumulator >= step {
+			ws.app.Update(step)
+			accumulator -= step
 		}
 
+		ws.profiler.Begin("clear")
 		ws.clearDisplay()
 
-		ws.render(rasterizer)
+		// How far between the last update and the next one this frame is
+		ws.profiler.Begin("render")
+		ws.app.Render(ws.rasterBuffer, accumulator/step)
 
+		ws.profiler.Begin("upload")
 		// This takes on average 5-7ms
 		// ws.texture.Update(nil, ws.pixels.Pix, ws.pixels.Stride)
-		ws.texture.Update(nil, ws.rasterBuffer.Pixels().Pix, ws.rasterBuffer.Pixels().Stride)
+		ws.frame = ws.shown()
+		ws.texture.Update(nil, ws.frame.Pix, ws.frame.Stride)
 		ws.renderer.Copy(ws.texture, nil, nil)
 
+		ws.profiler.Begin("text")
 		ws.txtFPSLabel.DrawAt(10, 10)
-		f := fmt.Sprintf("%2.2f", 1.0/elapsedTime*1000.0)
+		f := fmt.Sprintf("%2.2f (%2.2f ms)", ws.frameStats.Rate(), ws.frameStats.Average()*1000.0)
 		ws.dynaTxt.DrawAt(ws.txtFPSLabel.Bounds.W+10, 10, f)
 
 		// ws.mx, ws.my, _ = sdl.GetMouseState()
@@ -250,183 +467,59 @@ func (ws *WindowSurface) Run() {
 		f = fmt.Sprintf("<%d, %d>", ws.mx, ws.my)
 		ws.dynaTxt.DrawAt(ws.txtMousePos.Bounds.W+10, 25, f)
 
+		// Time spent working, excluding waiting for the next frame
 		ws.txtLoopLabel.DrawAt(10, 40)
-		f = fmt.Sprintf("%2.2f", loopTime)
+		f = fmt.Sprintf("%2.2f ms (%2.2f - %2.2f)", ws.loopStats.Average()*1000.0,
+			ws.loopStats.Min()*1000.0, ws.loopStats.Max()*1000.0)
 		ws.dynaTxt.DrawAt(ws.txtLoopLabel.Bounds.W+10, 40, f)
 
+		if ws.showDepth {
+			ws.txtDepthLabel.DrawAt(10, 55)
+			f = fmt.Sprintf("%g", ws.rasterBuffer.DepthAt(int(ws.mx), int(ws.my)))
+			ws.dynaTxt.DrawAt(ws.txtDepthLabel.Bounds.W+10, 55, f)
+		}
+
+		budget := framePeriod
+		if budget == 0.0 {
+			budget = 1.0 / 60.0
+		}
+		ws.profiler.Draw(ws.renderer, ws.nFont, ws.dynaTxt,
+			10, int32(ws.rasterBuffer.Pixels().Bounds().Dy())-10, budget)
+
+		ws.profiler.Begin("present")
+		ws.recordFrame(frameTime)
+		ws.captureFrame()
 		ws.renderer.Present()
+		ws.profiler.EndFrame()
+
+		ws.loopStats.Add(ws.seconds(sdl.GetPerformanceCounter() - frameStart))
 
-		// time.Sleep(time.Millisecond * 5)
-		loopTime = float64(time.Since(frameStart).Nanoseconds() / 1000000.0)
-		// elapsedTime = float64(time.Since(frameStart).Seconds())
-
-		sleepDelay = math.Floor(framePeriod - loopTime)
-		// fmt.Printf("%3.5f ,%3.5f, %3.5f \n", framePeriod, elapsedTime, sleepDelay)
-		if sleepDelay > 0 {
-			sdl.Delay(uint32(sleepDelay))
-			elapsedTime = framePeriod
-		} else {
-			elapsedTime = loopTime
+		if framePeriod > 0.0 {
+			ws.waitUntil(frameStart, framePeriod)
 		}
 	}
+
+	return nil
 }
 
-func (ws *WindowSurface) render(rasterizer api.IRasterizer) {
-	// c := color.RGBA{R: 255, G: 127, B: 0, A: 255}
-	// This full loop takes about 20ms for an 800x800 dimension.
-	// for y := 0; y < height; y++ {
-	// 	for x := 0; x < width; x++ {
-	// 		c.R = uint8(x % ws.mod)
-	// 		c.G = uint8(y % ws.mod)
-	// 		ws.rasterBuffer.SetPixelColor(c)
-	// 		ws.rasterBuffer.SetPixel(x, y, 0.0)
-	// 	}
-	// }
+// seconds converts a performance counter interval to seconds.
+func (ws *WindowSurface) seconds(counts uint64) float64 {
+	return float64(counts) / float64(sdl.GetPerformanceFrequency())
+}
 
-	x := 0
-	y := 0
-	left := false
-	rasterizer.DrawLineAmmeraal(ws.rasterBuffer, left, x, y, x+100, y+25) // blue dx>0
-
-	x = 0
-	y = 0
-	rasterizer.DrawLineAmmeraal(ws.rasterBuffer, left, x, y+25, x+100, y) // blue dx>0
-
-	x = 50
-	y = 50
-	down := true
-	rasterizer.DrawLineAmmeraal(ws.rasterBuffer, down, x, y+50, x+50, y+150) // red
-	x = 50
-	y = 50
-	rasterizer.DrawLineAmmeraal(ws.rasterBuffer, down, x+50, y+50, x, y+150) // red
-
-	// Horizontal
-	x = 100
-	y = 5
-	left = false
-	rasterizer.DrawLineAmmeraal(ws.rasterBuffer, left, x, y, x+100, y) // blue
-	x = 100
-	y = 10
-	left = true
-	rasterizer.DrawLineAmmeraal(ws.rasterBuffer, left, x, y, x+100, y) // blue
-
-	// Vertical
-	x = 100
-	y = 20
-	// down = false
-	// rasterizer.DrawLineAmmeraal(v, down, x, y+100, x, y) // red
-	// Or
-	down = true
-	rasterizer.DrawLineAmmeraal(ws.rasterBuffer, down, x, y, x, y+100) // red
-	x = 110
-	y = 20
-	down = false
-	rasterizer.DrawLineAmmeraal(ws.rasterBuffer, down, x, y, x, y+100) // red
-
-	ws.rasterBuffer.SetPixelColor(color.RGBA{R: 255, G: 255, B: 255, A: 255})
-
-	// Triangle flat-bottom ----------------------------------
-	x = 200
-	y = 25
-
-	x1 := 0
-	y1 := 50
-	x2 := 50
-	y2 := 50
-	x3 := 25
-	y3 := 0
-	// Make sure Y's are consitent
-	// rasterizer.Sort(&x1, &y1, &x2, &y2, &x3, &y3)
-
-	tri := graphics.NewTriangle()
-
-	down = false
-	// rasterizer.DrawLineAmmeraal(ws.rasterBuffer, left, x+x1, y+y1, x+x2, y+y2) // blue horz
-	// rasterizer.DrawLineAmmeraal(ws.rasterBuffer, down, x+x3, y+y3, x+x2, y+y2) // red
-	// rasterizer.DrawLineAmmeraal(ws.rasterBuffer, down, x+x3, y+y3, x+x1, y+y1) // red
-	tri.Set(x+x1, y+y1, x+x2, y+y2, x+x3, y+y3)
-	tri.Fill(ws.rasterBuffer)
-
-	ws.rasterBuffer.SetPixelColor(color.RGBA{R: 0, G: 255, B: 255, A: 127})
-	// Triangle flat-top ----------------------------------
-	x = 200
-	y = 50
-
-	x1 = 25
-	y1 = 50
-	x2 = 0
-	y2 = 0
-	x3 = 50
-	y3 = 0
-	// Make sure Y's are consitent
-	// rasterizer.Sort(&x1, &y1, &x2, &y2, &x3, &y3)
-
-	down = false
-	// rasterizer.DrawLineAmmeraal(ws.rasterBuffer, left, x+x1, y+y1, x+x2, y+y2) // blue horz
-	// rasterizer.DrawLineAmmeraal(ws.rasterBuffer, down, x+x2, y+y2, x+x3, y+y3) // red
-	// rasterizer.DrawLineAmmeraal(ws.rasterBuffer, down, x+x3, y+y3, x+x1, y+y1) // red
-
-	tri.SetWithZ(x+x1, y+y1, 2.0, x+x2, y+y2, 2.0, x+x3, y+y3, 2.0)
-	tri.Fill(ws.rasterBuffer)
-
-	ws.rasterBuffer.SetPixelColor(color.RGBA{R: 255, G: 255, B: 255, A: 255})
-
-	// Triangle split top ----------------------------------
-	x = 200
-	y = 200
-
-	x1 = 25
-	y1 = 50
-	x2 = 0
-	y2 = -50
-	x3 = 50
-	y3 = 0
-	tri.Set(x+x1, y+y1, x+x2, y+y2, x+x3, y+y3)
-	tri.Fill(ws.rasterBuffer)
-
-	// Triangle split bottom ----------------------------------
-	x = 350
-	y = 200
-
-	if ws.animate || ws.step {
-		if ws.xx2 < -50 {
-			ws.dir2 = 2
-		} else if ws.xx2 > 100 {
-			ws.dir2 = -2
+// waitUntil waits until 'period' seconds after 'start'. sdl.Delay only
+// has millisecond resolution and may oversleep, so it sleeps for most of
+// the wait and spins for the rest.
+func (ws *WindowSurface) waitUntil(start uint64, period float64) {
+	for {
+		remaining := period - ws.seconds(sdl.GetPerformanceCounter()-start)
+		if remaining <= 0.0 {
+			return
 		}
-		ws.xx2 += ws.dir2
-	}
-	x1 = ws.xx2
-
-	//y1 = 100
-	if ws.animate || ws.step {
-		if ws.xx3 < 0 {
-			ws.dir3 = 1
-		} else if ws.xx3 > 100 {
-			ws.dir3 = -1
+		if remaining > spinTime {
+			sdl.Delay(uint32((remaining - spinTime) * 1000.0))
 		}
-		ws.xx3 += ws.dir3
 	}
-	y1 = ws.xx3
-
-	if ws.animate || ws.step {
-		if ws.xx < -50 {
-			ws.dir = 1
-		} else if ws.xx > 100 {
-			ws.dir = -1
-		}
-		ws.xx += ws.dir
-	}
-	x2 = ws.xx // 75 cause overdraw, 50 is fine
-	// fmt.Println("x1 ", x1, "y1 ", y1, "x2 ", x2)
-	y2 = 50
-	x3 = 25
-	y3 = 0
-	// fmt.Println(x+x1, y+y1, x+x2, y+y2, x+x3, y+y3)
-	ws.step = false
-
-	tri.Set(x+x1, y+y1, x+x2, y+y2, x+x3, y+y3)
-	tri.Fill(ws.rasterBuffer)
 }
 
 // Quit stops the gui from running, effectively shutting it down.
@@ -442,14 +535,26 @@ func (ws *WindowSurface) Close() {
 	}
 	var err error
 
-	if ws.nFont == nil {
-		return
+	ws.StopRecording()
+	ws.writes.Wait()
+
+	// Without a font, from a failed SetFont, the HUD was never
+	// configured.
+	if ws.nFont != nil {
+		ws.txtSimStatus.Destroy()
+		ws.txtFPSLabel.Destroy()
+		ws.txtLoopLabel.Destroy()
+		ws.txtMousePos.Destroy()
+		ws.txtDepthLabel.Destroy()
+		ws.dynaTxt.Destroy()
+		ws.profiler.Destroy()
 	}
-	ws.nFont.Destroy()
 
-	ws.txtFPSLabel.Destroy()
-	ws.txtMousePos.Destroy()
-	ws.dynaTxt.Destroy()
+	// The manager may exist even if SetFont failed, and holds SDL_ttf
+	// open until closed.
+	if ws.fonts != nil {
+		ws.fonts.Close()
+	}
 
 	log.Println("Destroying texture")
 	err = ws.texture.Destroy()